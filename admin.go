@@ -0,0 +1,201 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// AdminStatus is runtime logging settings returned by AdminHandler
+type AdminStatus struct {
+	JSON   bool                        `json:"json"`
+	Levels map[string]AdminLevelStatus `json:"levels"`
+}
+
+// AdminLevelStatus is runtime logging settings of a level
+type AdminLevelStatus struct {
+	Mode      string `json:"mode"`
+	Color     bool   `json:"color"`
+	Trace     string `json:"trace"`
+	Timestamp bool   `json:"timestamp"`
+}
+
+// AdminUpdate is settings change accepted by AdminHandler, empty values are left unchanged
+type AdminUpdate struct {
+	// Level is global log level set by SetLevel
+	Level string `json:"level,omitempty"`
+	// JSON enables or disables JSON output
+	JSON *bool `json:"json,omitempty"`
+	// Trace is line trace mode of all levels
+	Trace string `json:"trace,omitempty"`
+	// Levels is mode and line trace mode per level keyed by level tag
+	Levels map[string]AdminLevelUpdate `json:"levels,omitempty"`
+}
+
+// AdminLevelUpdate is settings change of a level
+type AdminLevelUpdate struct {
+	Mode  string `json:"mode,omitempty"`
+	Trace string `json:"trace,omitempty"`
+}
+
+const maxAdminBodySize = 1 << 20
+
+// AdminHandler returns http.Handler which changes logging settings at runtime.
+// GET responds AdminStatus as JSON, PUT and POST apply AdminUpdate JSON body and respond the new AdminStatus.
+// e.g. curl -X PUT -d '{"level":"DEBG"}' http://localhost:8080/glg
+func (g *Glg) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			var u AdminUpdate
+			dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBodySize))
+			dec.DisallowUnknownFields()
+			err := dec.Decode(&u)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+				return
+			}
+			err = g.applyAdminUpdate(u)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPost}, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodHead {
+			return
+		}
+		err := json.NewEncoder(w).Encode(g.AdminStatus())
+		if err != nil {
+			g.handleError(fmt.Errorf("admin handler write error: %w", err))
+		}
+	})
+}
+
+// AdminHandler returns http.Handler which changes logging settings at runtime
+func AdminHandler() http.Handler {
+	return glg.AdminHandler()
+}
+
+// AdminStatus returns current logging settings of all levels
+func (g *Glg) AdminStatus() AdminStatus {
+	s := AdminStatus{
+		JSON:   g.enableJSON.Load(),
+		Levels: make(map[string]AdminLevelStatus),
+	}
+	g.logger.Range(func(lev LEVEL, l *logger) bool {
+		s.Levels[l.tag] = AdminLevelStatus{
+			Mode:      l.mode.String(),
+			Color:     l.isColor,
+			Trace:     l.traceMode.String(),
+			Timestamp: !l.disableTimestamp,
+		}
+		return true
+	})
+	return s
+}
+
+// applyAdminUpdate validates u and applies it, nothing is changed on error
+func (g *Glg) applyAdminUpdate(u AdminUpdate) error {
+	var (
+		level = UNKNOWN
+		trace traceMode
+		err   error
+	)
+	if u.Level != "" {
+		level, err = g.parseLevel(u.Level)
+		if err != nil {
+			return err
+		}
+	}
+	if u.Trace != "" {
+		trace, err = ParseTraceMode(u.Trace)
+		if err != nil {
+			return err
+		}
+	}
+	type levelUpdate struct {
+		level LEVEL
+		mode  MODE
+		trace traceMode
+	}
+	levels := make([]levelUpdate, 0, len(u.Levels))
+	for tag, lu := range u.Levels {
+		var (
+			up  levelUpdate
+			err error
+		)
+		up.level, err = g.parseLevel(tag)
+		if err != nil {
+			return err
+		}
+		if lu.Mode != "" {
+			up.mode, err = ParseMode(lu.Mode)
+		}
+		if err == nil && lu.Trace != "" {
+			up.trace, err = ParseTraceMode(lu.Trace)
+		}
+		if err != nil {
+			return fmt.Errorf("level %s: %w", tag, err)
+		}
+		levels = append(levels, up)
+	}
+	if u.Level == "" && u.JSON == nil && u.Trace == "" && len(levels) == 0 {
+		return errors.New("no settings to change")
+	}
+
+	if u.JSON != nil {
+		if *u.JSON {
+			g.EnableJSON()
+		} else {
+			g.DisableJSON()
+		}
+	}
+	if trace != 0 {
+		g.SetLineTraceMode(trace)
+	}
+	for _, up := range levels {
+		if up.mode != 0 {
+			g.SetLevelMode(up.level, up.mode)
+		}
+		if up.trace != 0 {
+			g.SetLevelLineTraceMode(up.level, up.trace)
+		}
+	}
+	if level != UNKNOWN {
+		g.SetLevel(level)
+	}
+	return nil
+}
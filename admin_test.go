@@ -0,0 +1,152 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestGlg_AdminHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		check      func(t *testing.T, g *Glg, s AdminStatus)
+	}{
+		{
+			name:       "get current settings",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, g *Glg, s AdminStatus) {
+				want := AdminLevelStatus{Mode: "NONE", Color: true, Trace: "none", Timestamp: true}
+				if got := s.Levels["DEBG"]; got != want {
+					t.Errorf("DEBG status = %+v, want %+v", got, want)
+				}
+				if got := s.Levels["ERR"].Trace; got != "short" {
+					t.Errorf("ERR trace = %v, want short", got)
+				}
+			},
+		},
+		{
+			name:       "enable debug level",
+			method:     http.MethodPut,
+			body:       `{"level":"DEBUG"}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, g *Glg, s AdminStatus) {
+				if got := g.GetCurrentMode(DEBG); got != STD {
+					t.Errorf("DEBG mode = %v, want STD", got)
+				}
+				if got := s.Levels["DEBG"].Mode; got != "STD" {
+					t.Errorf("DEBG status mode = %v, want STD", got)
+				}
+			},
+		},
+		{
+			name:       "change json, trace and level mode",
+			method:     http.MethodPost,
+			body:       `{"json":true,"trace":"long","levels":{"WARN":{"mode":"writer","trace":"short"}}}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, g *Glg, s AdminStatus) {
+				if !s.JSON || !g.enableJSON.Load() {
+					t.Error("JSON mode is not enabled")
+				}
+				if got := g.GetCurrentMode(WARN); got != WRITER {
+					t.Errorf("WARN mode = %v, want WRITER", got)
+				}
+				if got := s.Levels["WARN"].Trace; got != "short" {
+					t.Errorf("WARN trace = %v, want short", got)
+				}
+				if got := s.Levels["INFO"].Trace; got != "long" {
+					t.Errorf("INFO trace = %v, want long", got)
+				}
+			},
+		},
+		{
+			name:       "unknown level",
+			method:     http.MethodPut,
+			body:       `{"level":"LOUD"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid mode leaves settings unchanged",
+			method:     http.MethodPut,
+			body:       `{"json":true,"levels":{"INFO":{"mode":"FILE"}}}`,
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, g *Glg, _ AdminStatus) {
+				if g.enableJSON.Load() {
+					t.Error("JSON mode is changed by invalid request")
+				}
+			},
+		},
+		{
+			name:       "unknown field",
+			method:     http.MethodPut,
+			body:       `{"lvl":"DEBG"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty update",
+			method:     http.MethodPut,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodDelete,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New().SetLevel(INFO)
+			rec := httptest.NewRecorder()
+			g.AdminHandler().ServeHTTP(rec, httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			var s AdminStatus
+			if rec.Code == http.StatusOK {
+				if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, g, s)
+			}
+		})
+	}
+}
+
+func TestGlg_SetLevelRepeated(t *testing.T) {
+	g := New().SetLevel(WARN).SetLevel(INFO).SetLevel(DEBG)
+	if got := g.GetCurrentMode(DEBG); got != STD {
+		t.Errorf("GetCurrentMode(DEBG) = %v, want STD", got)
+	}
+}
@@ -0,0 +1,246 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy is async logging behavior when the destination queue is full
+type OverflowPolicy uint16
+
+const (
+	// OverflowBlock blocks logging call until the queue has space
+	OverflowBlock OverflowPolicy = iota + 1
+	// OverflowDropNewest drops the entry being logged
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued entry to make space
+	OverflowDropOldest
+	overflowDropBelow
+
+	// DefaultAsyncQueueSize is queue size used when EnableAsync is called with non positive size
+	DefaultAsyncQueueSize = 1024
+
+	flushInterval = time.Millisecond
+)
+
+// OverflowDropBelow returns policy which drops entries less severe than lv and blocks for the others
+func OverflowDropBelow(lv LEVEL) OverflowPolicy {
+	return overflowDropBelow | OverflowPolicy(lv)<<8
+}
+
+type asyncPipeline struct {
+	mu       sync.RWMutex
+	wg       sync.WaitGroup
+	pushing  sync.WaitGroup
+	queues   sync.Map
+	size     int
+	policy   OverflowPolicy
+	pending  int64
+	dropped  *uint64
+	closed   bool
+	onError  func(error)
+	severity func(LEVEL) Severity
+}
+
+type asyncEntry struct {
+	w  io.Writer
+	b  []byte
+	st *levelStats
+}
+
+// nonComparableWriter is queue key shared by the writers which cannot be map keys
+type nonComparableWriter struct{}
+
+// EnableAsync makes logging non-blocking.
+// Formatted entries are queued per destination writer and written by background goroutines,
+// when a queue is full the entry is handled by policy.
+// Write errors are passed to the error handler set by SetErrorHandler.
+func (g *Glg) EnableAsync(queueSize int, policy OverflowPolicy) *Glg {
+	if queueSize <= 0 {
+		queueSize = DefaultAsyncQueueSize
+	}
+	old := g.async.Swap(&asyncPipeline{
+		size:     queueSize,
+		policy:   policy,
+		dropped:  g.asyncDropped,
+		onError:  g.handleError,
+		severity: g.Severity,
+	})
+	if old != nil {
+		old.close()
+	}
+	return g
+}
+
+// DisableAsync writes all queued entries and turns back to synchronous logging
+func (g *Glg) DisableAsync() *Glg {
+	old := g.async.Swap(nil)
+	if old != nil {
+		old.close()
+	}
+	return g
+}
+
+// AsyncDropped returns the number of entries dropped by the overflow policy,
+// it is counted since the instance was created and is not reset by EnableAsync or DisableAsync.
+func (g *Glg) AsyncDropped() uint64 {
+	return atomic.LoadUint64(g.asyncDropped)
+}
+
+// Flush waits until all queued entries are written or ctx is done
+func (g *Glg) Flush(ctx context.Context) error {
+	p := g.async.Load()
+	if p == nil {
+		return nil
+	}
+	return p.flush(ctx)
+}
+
+// EnableAsync makes logging non-blocking
+func EnableAsync(queueSize int, policy OverflowPolicy) *Glg {
+	return glg.EnableAsync(queueSize, policy)
+}
+
+// Flush waits until all queued entries are written or ctx is done
+func Flush(ctx context.Context) error {
+	return glg.Flush(ctx)
+}
+
+func (p *asyncPipeline) push(level LEVEL, st *levelStats, w io.Writer, b []byte) {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		p.write(st, w, b)
+		return
+	}
+	q := p.queue(w)
+	// close waits for the running pushes before closing the queues,
+	// so the lock is not held while the push blocks on a full queue
+	p.pushing.Add(1)
+	p.mu.RUnlock()
+	defer p.pushing.Done()
+	e := asyncEntry{w: w, b: b, st: st}
+	atomic.AddInt64(&p.pending, 1)
+	switch policy := p.policy; {
+	case policy == OverflowDropNewest,
+		policy&0xff == overflowDropBelow && p.severity(level) < p.severity(LEVEL(policy>>8)):
+		select {
+		case q <- e:
+		default:
+			p.drop()
+		}
+	case policy == OverflowDropOldest:
+		for {
+			select {
+			case q <- e:
+				return
+			default:
+			}
+			select {
+			case <-q:
+				p.drop()
+			default:
+			}
+		}
+	default:
+		q <- e
+	}
+}
+
+func (p *asyncPipeline) drop() {
+	atomic.AddUint64(p.dropped, 1)
+	atomic.AddInt64(&p.pending, -1)
+}
+
+// queue returns queue for w and starts its writer goroutine on first use
+func (p *asyncPipeline) queue(w io.Writer) chan asyncEntry {
+	var key interface{} = w
+	if !reflect.TypeOf(w).Comparable() {
+		key = nonComparableWriter{}
+	}
+	if q, ok := p.queues.Load(key); ok {
+		return q.(chan asyncEntry)
+	}
+	q, loaded := p.queues.LoadOrStore(key, make(chan asyncEntry, p.size))
+	if !loaded {
+		p.wg.Add(1)
+		go p.run(q.(chan asyncEntry))
+	}
+	return q.(chan asyncEntry)
+}
+
+func (p *asyncPipeline) run(q chan asyncEntry) {
+	defer p.wg.Done()
+	for e := range q {
+		p.write(e.st, e.w, e.b)
+		atomic.AddInt64(&p.pending, -1)
+	}
+}
+
+// write writes b to w, counts the failure in st and passes the error to the error handler
+func (p *asyncPipeline) write(st *levelStats, w io.Writer, b []byte) {
+	_, err := w.Write(b)
+	if err != nil {
+		st.fail()
+		if p.onError != nil {
+			p.onError(fmt.Errorf("async write error: %w", err))
+		}
+	}
+}
+
+func (p *asyncPipeline) flush(ctx context.Context) error {
+	if atomic.LoadInt64(&p.pending) <= 0 {
+		return nil
+	}
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&p.pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (p *asyncPipeline) close() {
+	p.flush(context.Background())
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.pushing.Wait()
+	p.queues.Range(func(_, q interface{}) bool {
+		close(q.(chan asyncEntry))
+		return true
+	})
+	p.wg.Wait()
+}
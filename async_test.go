@@ -0,0 +1,296 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package glg
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockWriter blocks writes until release is closed
+type blockWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newBlockWriter() *blockWriter {
+	return &blockWriter{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (b *blockWriter) Write(p []byte) (int, error) {
+	b.once.Do(func() { close(b.started) })
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *blockWriter) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestGlg_EnableAsync(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableAsync(10, OverflowBlock)
+	defer g.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := g.Info("async", i, j); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := g.Flush(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if got := strings.Count(buf.String(), "async"); got != 100 {
+		t.Errorf("Glg.EnableAsync() written = %d, want %d", got, 100)
+	}
+	if got := g.AsyncDropped(); got != 0 {
+		t.Errorf("Glg.AsyncDropped() = %d, want 0", got)
+	}
+}
+
+func TestGlg_EnableAsyncJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableJSON().EnableAsync(0, OverflowBlock)
+	if err := g.With("k", "v").Info("async"); err != nil {
+		t.Error(err)
+	}
+	if err := g.Close(); err != nil {
+		t.Error(err)
+	}
+	if got, want := buf.String(), "\"detail\":\"async\",\"k\":\"v\"}\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("Glg.EnableAsync() json = %v, want suffix %v", got, want)
+	}
+}
+
+func TestGlg_EnableAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      OverflowPolicy
+		level       LEVEL
+		wantDropped uint64
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:        "drop newest",
+			policy:      OverflowDropNewest,
+			level:       INFO,
+			wantDropped: 3,
+			want:        []string{"msg0", "msg1", "msg2"},
+			notWant:     []string{"msg3", "msg4", "msg5"},
+		},
+		{
+			name:        "drop oldest",
+			policy:      OverflowDropOldest,
+			level:       INFO,
+			wantDropped: 3,
+			want:        []string{"msg0", "msg4", "msg5"},
+			notWant:     []string{"msg1", "msg2", "msg3"},
+		},
+		{
+			name:        "drop below level",
+			policy:      OverflowDropBelow(WARN),
+			level:       INFO,
+			wantDropped: 3,
+			want:        []string{"msg0", "msg1", "msg2"},
+			notWant:     []string{"msg3", "msg4", "msg5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newBlockWriter()
+			g := New().SetMode(WRITER).SetWriter(w).EnableAsync(2, tt.policy)
+			g.out(tt.level, "%s", "msg0")
+			<-w.started
+			for i := 1; i < 6; i++ {
+				g.out(tt.level, "%s", "msg"+strconv.Itoa(i))
+			}
+			if got := g.AsyncDropped(); got != tt.wantDropped {
+				t.Errorf("Glg.AsyncDropped() = %d, want %d", got, tt.wantDropped)
+			}
+			close(w.release)
+			if err := g.Flush(context.Background()); err != nil {
+				t.Error(err)
+			}
+			got := w.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output %v does not contain %v", got, want)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(got, nw) {
+					t.Errorf("output %v contains dropped %v", got, nw)
+				}
+			}
+			g.Close()
+		})
+	}
+
+	t.Run("drop below level blocks higher levels", func(t *testing.T) {
+		w := newBlockWriter()
+		g := New().SetMode(WRITER).SetWriter(w).EnableAsync(1, OverflowDropBelow(WARN))
+		g.Error("first")
+		<-w.started
+		g.Error("queued")
+		done := make(chan struct{})
+		go func() {
+			g.Error("blocked")
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Error("Glg.Error() did not block on full queue")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(w.release)
+		<-done
+		g.Close()
+		if got := w.String(); !strings.Contains(got, "blocked") || g.AsyncDropped() != 0 {
+			t.Errorf("output = %v, dropped = %d", got, g.AsyncDropped())
+		}
+	})
+}
+
+func TestGlg_EnableAsyncBlockedPush(t *testing.T) {
+	w := newBlockWriter()
+	g := New().SetMode(WRITER).SetWriter(w).EnableAsync(1, OverflowBlock)
+	p := g.async.Load()
+	g.Info("msg0")
+	<-w.started
+	g.Info("msg1")
+	go g.Info("msg2")
+	for atomic.LoadInt64(&p.pending) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	locked := make(chan struct{})
+	go func() {
+		p.mu.Lock()
+		p.mu.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Error("push blocked on the full queue holds the pipeline lock")
+	}
+	close(w.release)
+	if err := g.Close(); err != nil {
+		t.Error(err)
+	}
+	for _, want := range []string{"msg0", "msg1", "msg2"} {
+		if got := w.String(); !strings.Contains(got, want) {
+			t.Errorf("output %v does not contain %v", got, want)
+		}
+	}
+}
+
+func TestGlg_AsyncDropped(t *testing.T) {
+	w := newBlockWriter()
+	g := New().SetMode(WRITER).SetWriter(w).EnableAsync(1, OverflowDropNewest)
+	g.Info("written")
+	<-w.started
+	g.Info("queued")
+	g.Info("dropped")
+	close(w.release)
+	g.DisableAsync()
+	if got := g.AsyncDropped(); got != 1 {
+		t.Errorf("Glg.AsyncDropped() after DisableAsync = %d, want 1", got)
+	}
+	g.EnableAsync(1, OverflowDropNewest)
+	defer g.Close()
+	if got := g.AsyncDropped(); got != 1 {
+		t.Errorf("Glg.AsyncDropped() after EnableAsync = %d, want 1", got)
+	}
+}
+
+func TestGlg_Flush(t *testing.T) {
+	w := newBlockWriter()
+	g := New().SetMode(WRITER).SetWriter(w).EnableAsync(10, OverflowBlock)
+	g.Info("blocked")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := g.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Glg.Flush() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(w.release)
+	if err := g.Flush(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if err := New().Flush(context.Background()); err != nil {
+		t.Errorf("Glg.Flush() without async = %v", err)
+	}
+}
+
+func TestGlg_CloseAsync(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableAsync(10, OverflowBlock)
+	g.Info("queued")
+	if err := g.Close(); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(buf.String(), "queued") {
+		t.Errorf("Glg.Close() did not drain queue: %v", buf.String())
+	}
+	g.Info("sync")
+	if !strings.Contains(buf.String(), "sync") {
+		t.Errorf("Glg.Info() after Close = %v", buf.String())
+	}
+}
+
+func TestGlg_FatalAsync(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableAsync(10, OverflowBlock)
+	defer g.Close()
+	err := testExit(1, func() {
+		g.Fatal("fatal")
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(buf.String(), "fatal") {
+		t.Errorf("Glg.Fatal() did not flush queue: %v", buf.String())
+	}
+}
@@ -0,0 +1,491 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// Config is declarative glg configuration loadable from JSON and GLG_* environment variables
+type Config struct {
+	// Level is global log level such as "INFO", entries below it are disabled
+	Level string `json:"level,omitempty"`
+	// Mode is logging mode of all levels: "NONE", "STD", "WRITER" or "BOTH", it defaults to "BOTH" when File is set
+	Mode string `json:"mode,omitempty"`
+	// Color enables or disables color output of all levels
+	Color *bool `json:"color,omitempty"`
+	// Trace is line trace mode of all levels: "none", "short" or "long"
+	Trace string `json:"trace,omitempty"`
+	// Timestamp enables or disables timestamp output of all levels
+	Timestamp *bool `json:"timestamp,omitempty"`
+	// JSON enables or disables JSON output, the encoder is kept when it is not set
+	JSON *bool `json:"json,omitempty"`
+	// File is file destination of all levels
+	File *FileConfig `json:"file,omitempty"`
+	// Levels is configuration per level keyed by level tag
+	Levels map[string]*LevelConfig `json:"levels,omitempty"`
+}
+
+// LevelConfig is configuration of a level which overrides Config
+type LevelConfig struct {
+	Mode      string      `json:"mode,omitempty"`
+	Color     *bool       `json:"color,omitempty"`
+	Trace     string      `json:"trace,omitempty"`
+	Timestamp *bool       `json:"timestamp,omitempty"`
+	File      *FileConfig `json:"file,omitempty"`
+}
+
+// FileConfig is file destination configuration written by RotateFile
+type FileConfig struct {
+	// Path is file path which may contain strftime style directives
+	Path string `json:"path"`
+	// Perm is octal file permission such as "0644"
+	Perm string `json:"perm,omitempty"`
+	// MaxSize is the size in bytes which triggers rotation
+	MaxSize int64 `json:"max_size,omitempty"`
+	// Interval is rotation interval: "hourly", "daily" or duration such as "30m"
+	Interval string `json:"interval,omitempty"`
+	// MaxBackups is the number of rotated files to keep
+	MaxBackups int `json:"max_backups,omitempty"`
+	// MaxAge is the age of rotated files to keep such as "168h" or "7d"
+	MaxAge string `json:"max_age,omitempty"`
+	// Compress enables gzip compression of rotated files
+	Compress bool `json:"compress,omitempty"`
+}
+
+const (
+	envPrefix = "GLG_"
+
+	defaultFilePerm = 0o644
+)
+
+// LoadConfig reads JSON configuration file and overrides it by GLG_* environment variables.
+// Empty path loads the environment variables only.
+//
+// The environment variables are GLG_LEVEL, GLG_MODE, GLG_COLOR, GLG_TRACE, GLG_TIMESTAMP, GLG_JSON, GLG_FILE,
+// GLG_FILE_PERM, GLG_FILE_MAX_SIZE, GLG_FILE_INTERVAL, GLG_FILE_MAX_BACKUPS, GLG_FILE_MAX_AGE, GLG_FILE_COMPRESS,
+// and the per level variables GLG_<TAG>_MODE, GLG_<TAG>_COLOR, GLG_<TAG>_TRACE, GLG_<TAG>_TIMESTAMP,
+// GLG_<TAG>_FILE and GLG_<TAG>_FILE_* such as GLG_ERR_FILE=/var/log/err.log.
+func LoadConfig(path string) (*Config, error) {
+	cfg := new(Config)
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		err = json.Unmarshal(b, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+	err := cfg.LoadEnv()
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadEnv overrides the configuration by GLG_* environment variables
+func (c *Config) LoadEnv() error {
+	var errs error
+	for _, kv := range os.Environ() {
+		key, val, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, envPrefix) {
+			continue
+		}
+		err := c.setEnv(strings.TrimPrefix(key, envPrefix), val)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid environment variable %s=%s: %w", key, val, err))
+		}
+	}
+	return errs
+}
+
+func (c *Config) setEnv(key, val string) (err error) {
+	switch key {
+	case "LEVEL":
+		c.Level = val
+		return nil
+	case "JSON":
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		c.JSON = &b
+		return nil
+	}
+	ok, err := setCommonEnv(key, val, &c.Mode, &c.Color, &c.Trace, &c.Timestamp, &c.File)
+	if ok || err != nil {
+		return err
+	}
+	tag, lkey, found := strings.Cut(key, "_")
+	if !found {
+		return nil
+	}
+	if c.Levels == nil {
+		c.Levels = make(map[string]*LevelConfig)
+	}
+	lc, exists := c.Levels[tag]
+	if !exists {
+		lc = new(LevelConfig)
+	}
+	ok, err = setCommonEnv(lkey, val, &lc.Mode, &lc.Color, &lc.Trace, &lc.Timestamp, &lc.File)
+	if ok && !exists {
+		c.Levels[tag] = lc
+	}
+	return err
+}
+
+// setCommonEnv sets the variable shared by Config and LevelConfig, ok is false for unknown key
+func setCommonEnv(key, val string, mode *string, color **bool, trace *string, timestamp **bool, file **FileConfig) (ok bool, err error) {
+	switch key {
+	case "MODE":
+		*mode = val
+	case "TRACE":
+		*trace = val
+	case "COLOR", "TIMESTAMP":
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return true, err
+		}
+		if key == "COLOR" {
+			*color = &b
+		} else {
+			*timestamp = &b
+		}
+	case "FILE":
+		if *file == nil {
+			*file = new(FileConfig)
+		}
+		(*file).Path = val
+	default:
+		opt, found := strings.CutPrefix(key, "FILE_")
+		if !found {
+			return false, nil
+		}
+		if *file == nil {
+			*file = new(FileConfig)
+		}
+		return true, (*file).setEnv(opt, val)
+	}
+	return true, nil
+}
+
+func (f *FileConfig) setEnv(key, val string) (err error) {
+	switch key {
+	case "PERM":
+		f.Perm = val
+	case "MAX_SIZE":
+		f.MaxSize, err = strconv.ParseInt(val, 10, 64)
+	case "INTERVAL":
+		f.Interval = val
+	case "MAX_BACKUPS":
+		f.MaxBackups, err = strconv.Atoi(val)
+	case "MAX_AGE":
+		f.MaxAge = val
+	case "COMPRESS":
+		f.Compress, err = strconv.ParseBool(val)
+	default:
+		return fmt.Errorf("unknown file option %s", key)
+	}
+	return err
+}
+
+// NewWithConfig returns glg instance built from the configuration
+func NewWithConfig(cfg *Config) (*Glg, error) {
+	g := New()
+	err := g.Apply(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Apply configures glg instance by the configuration.
+// The configuration is validated and the files are opened before any change is made.
+// The levels writing to the files are set to BOTH mode unless the mode is configured.
+func (g *Glg) Apply(cfg *Config) error {
+	if cfg == nil {
+		return errors.New("config must not be nil")
+	}
+	var (
+		level = UNKNOWN
+		mode  MODE
+		trace traceMode
+		err   error
+	)
+	if cfg.Level != "" {
+		level, err = g.parseLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.Mode != "" {
+		mode, err = ParseMode(cfg.Mode)
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.Trace != "" {
+		trace, err = ParseTraceMode(cfg.Trace)
+		if err != nil {
+			return err
+		}
+	}
+	type levelSetting struct {
+		level LEVEL
+		mode  MODE
+		trace traceMode
+		cfg   *LevelConfig
+	}
+	levels := make([]levelSetting, 0, len(cfg.Levels))
+	for tag, lc := range cfg.Levels {
+		if lc == nil {
+			continue
+		}
+		ls := levelSetting{cfg: lc}
+		ls.level, err = g.parseLevel(tag)
+		if err != nil {
+			return err
+		}
+		if lc.Mode != "" {
+			ls.mode, err = ParseMode(lc.Mode)
+			if err != nil {
+				return fmt.Errorf("level %s: %w", tag, err)
+			}
+		}
+		if lc.Trace != "" {
+			ls.trace, err = ParseTraceMode(lc.Trace)
+			if err != nil {
+				return fmt.Errorf("level %s: %w", tag, err)
+			}
+		}
+		levels = append(levels, ls)
+	}
+
+	files := make(map[string]*RotateFile)
+	fileWriter := func(fc *FileConfig) (io.Writer, error) {
+		if fc == nil || fc.Path == "" {
+			return nil, nil
+		}
+		if f, ok := files[fc.Path]; ok {
+			return f, nil
+		}
+		f, err := fc.rotateFile()
+		if err != nil {
+			return nil, err
+		}
+		files[fc.Path] = f
+		return f, nil
+	}
+	closeFiles := func(err error) error {
+		for _, f := range files {
+			err = errors.Join(err, f.Close())
+		}
+		return err
+	}
+	writer, err := fileWriter(cfg.File)
+	if err != nil {
+		return closeFiles(err)
+	}
+	// the files are written only in WRITER or BOTH mode, so BOTH is used when the mode is not set
+	if mode == 0 && writer != nil {
+		mode = BOTH
+	}
+	writers := make([]io.Writer, len(levels))
+	for i, ls := range levels {
+		writers[i], err = fileWriter(ls.cfg.File)
+		if err != nil {
+			return closeFiles(err)
+		}
+		if ls.mode == 0 && mode == 0 && writers[i] != nil {
+			levels[i].mode = BOTH
+		}
+	}
+
+	if cfg.JSON != nil {
+		if *cfg.JSON {
+			g.EnableJSON()
+		} else {
+			g.DisableJSON()
+		}
+	}
+	if mode != 0 {
+		g.SetMode(mode)
+	}
+	if cfg.Color != nil {
+		if *cfg.Color {
+			g.EnableColor()
+		} else {
+			g.DisableColor()
+		}
+	}
+	if trace != 0 {
+		g.SetLineTraceMode(trace)
+	}
+	if cfg.Timestamp != nil {
+		if *cfg.Timestamp {
+			g.EnableTimestamp()
+		} else {
+			g.DisableTimestamp()
+		}
+	}
+	if writer != nil {
+		g.SetWriter(writer)
+	}
+	for i, ls := range levels {
+		if ls.mode != 0 {
+			g.SetLevelMode(ls.level, ls.mode)
+		}
+		if ls.cfg.Color != nil {
+			if *ls.cfg.Color {
+				g.EnableLevelColor(ls.level)
+			} else {
+				g.DisableLevelColor(ls.level)
+			}
+		}
+		if ls.trace != 0 {
+			g.SetLevelLineTraceMode(ls.level, ls.trace)
+		}
+		if ls.cfg.Timestamp != nil {
+			if *ls.cfg.Timestamp {
+				g.EnableLevelTimestamp(ls.level)
+			} else {
+				g.DisableLevelTimestamp(ls.level)
+			}
+		}
+		if writers[i] != nil {
+			g.SetLevelWriter(ls.level, writers[i])
+		}
+	}
+	if level != UNKNOWN {
+		g.SetLevel(level)
+	}
+	return nil
+}
+
+// Apply configures glg instance by the configuration
+func Apply(cfg *Config) error {
+	return glg.Apply(cfg)
+}
+
+func (f *FileConfig) rotateFile() (*RotateFile, error) {
+	perm := os.FileMode(defaultFilePerm)
+	if f.Perm != "" {
+		p, err := strconv.ParseUint(f.Perm, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file permission %s: %w", f.Perm, err)
+		}
+		perm = os.FileMode(p)
+	}
+	r := NewRotateFile(f.Path, perm).
+		SetMaxSize(f.MaxSize).
+		SetMaxBackups(f.MaxBackups)
+	if f.Interval != "" {
+		interval, err := parseDuration(f.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rotate interval %s: %w", f.Interval, err)
+		}
+		r.SetRotateInterval(interval)
+	}
+	if f.MaxAge != "" {
+		age, err := parseDuration(f.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max age %s: %w", f.MaxAge, err)
+		}
+		r.SetMaxAge(age)
+	}
+	if f.Compress {
+		r.EnableCompress()
+	}
+	// open the file to report the error on configuration
+	err := r.Reopen()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parseLevel converts level string to LEVEL, unknown level is error
+func (g *Glg) parseLevel(tag string) (LEVEL, error) {
+	lv := g.TagStringToLevel(tag)
+	if lv == UNKNOWN {
+		return UNKNOWN, fmt.Errorf("unknown log level %s", tag)
+	}
+	return lv, nil
+}
+
+// ParseMode converts mode string such as "STD" to MODE
+func ParseMode(mode string) (MODE, error) {
+	switch strings.ToUpper(strings.TrimSpace(mode)) {
+	case "NONE":
+		return NONE, nil
+	case "STD":
+		return STD, nil
+	case "BOTH":
+		return BOTH, nil
+	case "WRITER":
+		return WRITER, nil
+	}
+	return 0, fmt.Errorf("unknown log mode %s", mode)
+}
+
+// ParseTraceMode converts line trace mode string "none", "short" or "long" to trace mode
+func ParseTraceMode(mode string) (traceMode, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "none":
+		return TraceLineNone, nil
+	case "short":
+		return TraceLineShort, nil
+	case "long":
+		return TraceLineLong, nil
+	}
+	return 0, fmt.Errorf("unknown line trace mode %s", mode)
+}
+
+// parseDuration parses duration string which also accepts "hourly", "daily" and days such as "7d"
+func parseDuration(s string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "hourly":
+		return RotateHourly, nil
+	case "daily":
+		return RotateDaily, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n * float64(RotateDaily)), nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,300 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name: "json file",
+			file: `{"level":"WARN","json":true,"color":false,"file":{"path":"app.log","max_size":1024,"interval":"daily"},
+				"levels":{"ERR":{"mode":"both","trace":"short","file":{"path":"err.log","max_backups":3,"compress":true}}}}`,
+			want: &Config{
+				Level: "WARN",
+				JSON:  boolPtr(true),
+				Color: boolPtr(false),
+				File:  &FileConfig{Path: "app.log", MaxSize: 1024, Interval: "daily"},
+				Levels: map[string]*LevelConfig{
+					"ERR": {Mode: "both", Trace: "short", File: &FileConfig{Path: "err.log", MaxBackups: 3, Compress: true}},
+				},
+			},
+		},
+		{
+			name: "env only",
+			env: map[string]string{
+				"GLG_LEVEL":             "DEBG",
+				"GLG_TIMESTAMP":         "false",
+				"GLG_FILE":              "app.log",
+				"GLG_FILE_MAX_AGE":      "7d",
+				"GLG_INFO_MODE":         "WRITER",
+				"GLG_ERR_FILE":          "err.log",
+				"GLG_ERR_FILE_MAX_SIZE": "10",
+			},
+			want: &Config{
+				Level:     "DEBG",
+				Timestamp: boolPtr(false),
+				File:      &FileConfig{Path: "app.log", MaxAge: "7d"},
+				Levels: map[string]*LevelConfig{
+					"INFO": {Mode: "WRITER"},
+					"ERR":  {File: &FileConfig{Path: "err.log", MaxSize: 10}},
+				},
+			},
+		},
+		{
+			name: "env overrides json file",
+			file: `{"level":"WARN","levels":{"ERR":{"mode":"STD"}}}`,
+			env:  map[string]string{"GLG_LEVEL": "INFO", "GLG_JSON": "true", "GLG_ERR_COLOR": "true"},
+			want: &Config{
+				Level:  "INFO",
+				JSON:   boolPtr(true),
+				Levels: map[string]*LevelConfig{"ERR": {Mode: "STD", Color: boolPtr(true)}},
+			},
+		},
+		{
+			name:    "invalid json",
+			file:    `{"level":`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid env bool",
+			env:     map[string]string{"GLG_JSON": "maybe"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown file option",
+			env:     map[string]string{"GLG_FILE_SIZE": "10"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, kv := range os.Environ() {
+				if key, _, _ := strings.Cut(kv, "="); strings.HasPrefix(key, envPrefix) {
+					t.Setenv(key, "")
+					os.Unsetenv(key)
+				}
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var path string
+			if tt.file != "" {
+				path = filepath.Join(t.TempDir(), "glg.json")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := LoadConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_Apply(t *testing.T) {
+	dir := t.TempDir()
+	appLog := filepath.Join(dir, "app.log")
+	errLog := filepath.Join(dir, "err.log")
+	disabled := false
+	cfg := &Config{
+		Level:     "INFO",
+		Mode:      "WRITER",
+		Trace:     "none",
+		Timestamp: &disabled,
+		File:      &FileConfig{Path: appLog, Perm: "0600"},
+		Levels: map[string]*LevelConfig{
+			"ERR":  {File: &FileConfig{Path: errLog, MaxSize: 1 << 20}},
+			"WARN": {Mode: "NONE"},
+		},
+	}
+	g, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Debug("debug")
+	g.Info("info")
+	g.Warn("warn")
+	g.Error("error")
+
+	if got, want := readFile(t, appLog), "[INFO]:\tinfo\n"; got != want {
+		t.Errorf("app log = %q, want %q", got, want)
+	}
+	if got, want := readFile(t, errLog), "[ERR]:\terror\n"; got != want {
+		t.Errorf("err log = %q, want %q", got, want)
+	}
+	if fi, err := os.Stat(appLog); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("app log mode = %v, %v", fi.Mode().Perm(), err)
+	}
+	if got := g.GetCurrentMode(WARN); got != NONE {
+		t.Errorf("WARN mode = %v, want %v", got, NONE)
+	}
+}
+
+func TestGlg_ApplyFileMode(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   func(path string) *Config
+		level LEVEL
+		want  MODE
+	}{
+		{
+			name:  "file of all levels",
+			cfg:   func(path string) *Config { return &Config{Level: "INFO", File: &FileConfig{Path: path}} },
+			level: INFO,
+			want:  BOTH,
+		},
+		{
+			name: "file of level",
+			cfg: func(path string) *Config {
+				return &Config{Levels: map[string]*LevelConfig{"INFO": {File: &FileConfig{Path: path}}}}
+			},
+			level: INFO,
+			want:  BOTH,
+		},
+		{
+			name: "file with mode",
+			cfg: func(path string) *Config {
+				return &Config{Mode: "WRITER", File: &FileConfig{Path: path}}
+			},
+			level: INFO,
+			want:  WRITER,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			g := New().DisableTimestamp().SetLineTraceMode(TraceLineNone)
+			if err := g.Apply(tt.cfg(path)); err != nil {
+				t.Fatal(err)
+			}
+			g.Info("info")
+			if err := g.Close(); err != nil {
+				t.Error(err)
+			}
+			if got := g.GetCurrentMode(tt.level); got != tt.want {
+				t.Errorf("mode = %v, want %v", got, tt.want)
+			}
+			if got, want := readFile(t, path), "[INFO]:\tinfo\n"; got != want {
+				t.Errorf("file = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGlg_ApplyEncoder(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name string
+		glg  func() *Glg
+		json *bool
+		want string
+	}{
+		{name: "json is kept", glg: func() *Glg { return New().EnableJSON() }, want: `{"level":"INFO","detail":"msg"}` + "\n"},
+		{name: "encoder is kept", glg: func() *Glg { return New().SetEncoder(LogfmtEncoder) }, want: "level=INFO msg=msg\n"},
+		{name: "json is enabled", glg: New, json: &enabled, want: `{"level":"INFO","detail":"msg"}` + "\n"},
+		{name: "json is disabled", glg: func() *Glg { return New().EnableJSON() }, json: &disabled, want: "[INFO]:\tmsg\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := tt.glg().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineNone)
+			if err := g.Apply(&Config{Level: "INFO", JSON: tt.json}); err != nil {
+				t.Fatal(err)
+			}
+			g.Info("msg")
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output after Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_ApplyError(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{name: "nil config"},
+		{name: "unknown level", cfg: &Config{Level: "LOUD"}},
+		{name: "unknown mode", cfg: &Config{Mode: "FILE"}},
+		{name: "unknown trace mode", cfg: &Config{Trace: "full"}},
+		{name: "unknown level key", cfg: &Config{Levels: map[string]*LevelConfig{"LOUD": {}}}},
+		{name: "unknown level mode", cfg: &Config{Levels: map[string]*LevelConfig{"ERR": {Mode: "FILE"}}}},
+		{name: "invalid permission", cfg: &Config{File: &FileConfig{Path: "app.log", Perm: "rw"}}},
+		{name: "invalid interval", cfg: &Config{File: &FileConfig{Path: "app.log", Interval: "weekly"}}},
+		{name: "unwritable file", cfg: &Config{JSON: &enabled, File: &FileConfig{Path: "/dev/null/app.log"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New()
+			if err := g.Apply(tt.cfg); err == nil {
+				t.Error("Apply() error = nil, want error")
+			}
+			if g.enableJSON.Load() {
+				t.Error("Apply() changed the instance on error")
+			}
+		})
+	}
+}
+
+func Test_parseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{in: "hourly", want: RotateHourly},
+		{in: "Daily", want: RotateDaily},
+		{in: "7d", want: 7 * RotateDaily},
+		{in: "90m", want: 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDuration(tt.in)
+			if err != nil || got != tt.want {
+				t.Errorf("parseDuration(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
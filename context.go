@@ -0,0 +1,568 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+)
+
+// ContextExtractor returns fields extracted from context.Context
+type ContextExtractor func(ctx context.Context) []Field
+
+type contextFieldsKey struct{}
+
+// ContextWithFields returns copy of ctx which carries given key/value pairs.
+// The pairs are attached to the log entries written by *Ctx functions.
+func ContextWithFields(ctx context.Context, kv ...interface{}) context.Context {
+	fields := FieldsFromContext(ctx)
+	return context.WithValue(ctx, contextFieldsKey{}, appendFields(fields[:len(fields):len(fields)], kv...))
+}
+
+// FieldsFromContext returns key/value pairs stored by ContextWithFields
+func FieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextFieldsKey{}).([]Field)
+	return fields
+}
+
+// AddContextExtractor registers extractor whose fields are attached to the log entries written by *Ctx functions
+func (g *Glg) AddContextExtractor(fn ContextExtractor) *Glg {
+	if fn == nil {
+		return g
+	}
+	for {
+		old := g.extractors.Load()
+		var exs []ContextExtractor
+		if old != nil {
+			exs = make([]ContextExtractor, len(*old), len(*old)+1)
+			copy(exs, *old)
+		}
+		exs = append(exs, fn)
+		if g.extractors.CompareAndSwap(old, &exs) {
+			return g
+		}
+	}
+}
+
+// AddContextExtractor registers extractor whose fields are attached to the log entries written by *Ctx functions
+func AddContextExtractor(fn ContextExtractor) *Glg {
+	return glg.AddContextExtractor(fn)
+}
+
+// contextFields returns instance fields followed by the fields stored in ctx and the extracted ones
+func (g *Glg) contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return g.fields
+	}
+	cf := FieldsFromContext(ctx)
+	exs := g.extractors.Load()
+	if len(cf) == 0 && (exs == nil || len(*exs) == 0) {
+		return g.fields
+	}
+	fields := make([]Field, len(g.fields), len(g.fields)+len(cf))
+	copy(fields, g.fields)
+	fields = append(fields, cf...)
+	if exs != nil {
+		for _, fn := range *exs {
+			fields = append(fields, fn(ctx)...)
+		}
+	}
+	return fields
+}
+
+// LogCtx outputs Log level log with fields from ctx
+func (g *Glg) LogCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(LOG, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// LogfCtx outputs formatted Log level log with fields from ctx
+func (g *Glg) LogfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(LOG, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// LogFuncCtx outputs Log level log returned from the function with fields from ctx
+func (g *Glg) LogFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(LOG) {
+		return g.output(LOG, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// LogCtx outputs Log level log with fields from ctx
+func LogCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(LOG, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// LogfCtx outputs formatted Log level log with fields from ctx
+func LogfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(LOG, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// LogFuncCtx outputs Log level log returned from the function with fields from ctx
+func LogFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(LOG) {
+		return glg.output(LOG, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// InfoCtx outputs Info level log with fields from ctx
+func (g *Glg) InfoCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(INFO, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// InfofCtx outputs formatted Info level log with fields from ctx
+func (g *Glg) InfofCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(INFO, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// InfoFuncCtx outputs Info level log returned from the function with fields from ctx
+func (g *Glg) InfoFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(INFO) {
+		return g.output(INFO, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// InfoCtx outputs Info level log with fields from ctx
+func InfoCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(INFO, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// InfofCtx outputs formatted Info level log with fields from ctx
+func InfofCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(INFO, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// InfoFuncCtx outputs Info level log returned from the function with fields from ctx
+func InfoFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(INFO) {
+		return glg.output(INFO, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// SuccessCtx outputs Success level log with fields from ctx
+func (g *Glg) SuccessCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(OK, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// SuccessfCtx outputs formatted Success level log with fields from ctx
+func (g *Glg) SuccessfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(OK, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// SuccessFuncCtx outputs Success level log returned from the function with fields from ctx
+func (g *Glg) SuccessFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(OK) {
+		return g.output(OK, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// SuccessCtx outputs Success level log with fields from ctx
+func SuccessCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(OK, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// SuccessfCtx outputs formatted Success level log with fields from ctx
+func SuccessfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(OK, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// SuccessFuncCtx outputs Success level log returned from the function with fields from ctx
+func SuccessFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(OK) {
+		return glg.output(OK, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// DebugCtx outputs Debug level log with fields from ctx
+func (g *Glg) DebugCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(DEBG, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// DebugfCtx outputs formatted Debug level log with fields from ctx
+func (g *Glg) DebugfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(DEBG, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// DebugFuncCtx outputs Debug level log returned from the function with fields from ctx
+func (g *Glg) DebugFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(DEBG) {
+		return g.output(DEBG, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// DebugCtx outputs Debug level log with fields from ctx
+func DebugCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(DEBG, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// DebugfCtx outputs formatted Debug level log with fields from ctx
+func DebugfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(DEBG, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// DebugFuncCtx outputs Debug level log returned from the function with fields from ctx
+func DebugFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(DEBG) {
+		return glg.output(DEBG, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// WarnCtx outputs Warn level log with fields from ctx
+func (g *Glg) WarnCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(WARN, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// WarnfCtx outputs formatted Warn level log with fields from ctx
+func (g *Glg) WarnfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(WARN, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// WarnFuncCtx outputs Warn level log returned from the function with fields from ctx
+func (g *Glg) WarnFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(WARN) {
+		return g.output(WARN, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// WarnCtx outputs Warn level log with fields from ctx
+func WarnCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(WARN, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// WarnfCtx outputs formatted Warn level log with fields from ctx
+func WarnfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(WARN, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// WarnFuncCtx outputs Warn level log returned from the function with fields from ctx
+func WarnFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(WARN) {
+		return glg.output(WARN, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// TraceCtx outputs Trace level log with fields from ctx
+func (g *Glg) TraceCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(TRACE, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// TracefCtx outputs formatted Trace level log with fields from ctx
+func (g *Glg) TracefCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(TRACE, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// TraceFuncCtx outputs Trace level log returned from the function with fields from ctx
+func (g *Glg) TraceFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(TRACE) {
+		return g.output(TRACE, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// TraceCtx outputs Trace level log with fields from ctx
+func TraceCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(TRACE, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// TracefCtx outputs formatted Trace level log with fields from ctx
+func TracefCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(TRACE, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// TraceFuncCtx outputs Trace level log returned from the function with fields from ctx
+func TraceFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(TRACE) {
+		return glg.output(TRACE, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// PrintCtx outputs Print log with fields from ctx
+func (g *Glg) PrintCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(PRINT, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// PrintlnCtx outputs fixed line Print log with fields from ctx
+func (g *Glg) PrintlnCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(PRINT, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// PrintfCtx outputs formatted Print log with fields from ctx
+func (g *Glg) PrintfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(PRINT, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// PrintFuncCtx outputs Print log returned from the function with fields from ctx
+func (g *Glg) PrintFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(PRINT) {
+		return g.output(PRINT, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// PrintCtx outputs Print log with fields from ctx
+func PrintCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(PRINT, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// PrintlnCtx outputs fixed line Print log with fields from ctx
+func PrintlnCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(PRINT, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// PrintfCtx outputs formatted Print log with fields from ctx
+func PrintfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(PRINT, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// PrintFuncCtx outputs Print log returned from the function with fields from ctx
+func PrintFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(PRINT) {
+		return glg.output(PRINT, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// ErrorCtx outputs Error log with fields from ctx
+func (g *Glg) ErrorCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(ERR, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// ErrorfCtx outputs formatted Error log with fields from ctx
+func (g *Glg) ErrorfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(ERR, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// ErrorFuncCtx outputs Error log returned from the function with fields from ctx
+func (g *Glg) ErrorFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(ERR) {
+		return g.output(ERR, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// ErrorCtx outputs Error log with fields from ctx
+func ErrorCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(ERR, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// ErrorfCtx outputs formatted Error log with fields from ctx
+func ErrorfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(ERR, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// ErrorFuncCtx outputs Error log returned from the function with fields from ctx
+func ErrorFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(ERR) {
+		return glg.output(ERR, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// FailCtx outputs Failed log with fields from ctx
+func (g *Glg) FailCtx(ctx context.Context, val ...interface{}) error {
+	return g.output(FAIL, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// FailfCtx outputs formatted Failed log with fields from ctx
+func (g *Glg) FailfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return g.output(FAIL, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// FailFuncCtx outputs Failed log returned from the function with fields from ctx
+func (g *Glg) FailFuncCtx(ctx context.Context, f func() string) error {
+	if g.isModeEnable(FAIL) {
+		return g.output(FAIL, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// FailCtx outputs Failed log with fields from ctx
+func FailCtx(ctx context.Context, val ...interface{}) error {
+	return glg.output(FAIL, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// FailfCtx outputs formatted Failed log with fields from ctx
+func FailfCtx(ctx context.Context, format string, val ...interface{}) error {
+	return glg.output(FAIL, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// FailFuncCtx outputs Failed log returned from the function with fields from ctx
+func FailFuncCtx(ctx context.Context, f func() string) error {
+	if isModeEnable(FAIL) {
+		return glg.output(FAIL, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// CustomLogCtx outputs custom level log with fields from ctx
+func (g *Glg) CustomLogCtx(ctx context.Context, level string, val ...interface{}) error {
+	return g.output(g.TagStringToLevel(level), g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// CustomLogfCtx outputs formatted custom level log with fields from ctx
+func (g *Glg) CustomLogfCtx(ctx context.Context, level string, format string, val ...interface{}) error {
+	return g.output(g.TagStringToLevel(level), g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+}
+
+// CustomLogFuncCtx outputs custom level log returned from the function with fields from ctx
+func (g *Glg) CustomLogFuncCtx(ctx context.Context, level string, f func() string) error {
+	lv := g.TagStringToLevel(level)
+	if g.isModeEnable(lv) {
+		return g.output(lv, g.callerDepth(), 0, g.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// CustomLogCtx outputs custom level log with fields from ctx
+func CustomLogCtx(ctx context.Context, level string, val ...interface{}) error {
+	return glg.output(glg.TagStringToLevel(level), glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// CustomLogfCtx outputs formatted custom level log with fields from ctx
+func CustomLogfCtx(ctx context.Context, level string, format string, val ...interface{}) error {
+	return glg.output(glg.TagStringToLevel(level), glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+}
+
+// CustomLogFuncCtx outputs custom level log returned from the function with fields from ctx
+func CustomLogFuncCtx(ctx context.Context, level string, f func() string) error {
+	lv := TagStringToLevel(level)
+	if isModeEnable(lv) {
+		return glg.output(lv, glg.callerDepth(), 0, glg.contextFields(ctx), "%s", f())
+	}
+	return nil
+}
+
+// FatalCtx outputs Failed log with fields from ctx and exit program
+func (g *Glg) FatalCtx(ctx context.Context, val ...interface{}) {
+	err := g.output(FATAL, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+	if err != nil {
+		err = g.out(ERR, g.blankFormat(1), err.Error())
+		if err != nil {
+			panic(err)
+		}
+	}
+	g.fatalExit(1)
+}
+
+// FatallnCtx outputs line fixed Failed log with fields from ctx and exit program
+func (g *Glg) FatallnCtx(ctx context.Context, val ...interface{}) {
+	err := g.output(FATAL, g.callerDepth(), 0, g.contextFields(ctx), g.blankFormat(len(val)), val...)
+	if err != nil {
+		err = g.out(ERR, g.blankFormat(1), err.Error())
+		if err != nil {
+			panic(err)
+		}
+	}
+	g.fatalExit(1)
+}
+
+// FatalfCtx outputs formatted Failed log with fields from ctx and exit program
+func (g *Glg) FatalfCtx(ctx context.Context, format string, val ...interface{}) {
+	err := g.output(FATAL, g.callerDepth(), 0, g.contextFields(ctx), format, val...)
+	if err != nil {
+		err = g.out(ERR, g.blankFormat(1), err.Error())
+		if err != nil {
+			panic(err)
+		}
+	}
+	g.fatalExit(1)
+}
+
+// FatalCtx outputs Failed log with fields from ctx and exit program
+func FatalCtx(ctx context.Context, val ...interface{}) {
+	err := glg.output(FATAL, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+	if err != nil {
+		err = glg.out(ERR, glg.blankFormat(1), err.Error())
+		if err != nil {
+			panic(err)
+		}
+	}
+	glg.fatalExit(1)
+}
+
+// FatallnCtx outputs line fixed Failed log with fields from ctx and exit program
+func FatallnCtx(ctx context.Context, val ...interface{}) {
+	err := glg.output(FATAL, glg.callerDepth(), 0, glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+	if err != nil {
+		err = glg.out(ERR, glg.blankFormat(1), err.Error())
+		if err != nil {
+			panic(err)
+		}
+	}
+	glg.fatalExit(1)
+}
+
+// FatalfCtx outputs formatted Failed log with fields from ctx and exit program
+func FatalfCtx(ctx context.Context, format string, val ...interface{}) {
+	err := glg.output(FATAL, glg.callerDepth(), 0, glg.contextFields(ctx), format, val...)
+	if err != nil {
+		err = glg.out(ERR, glg.blankFormat(1), err.Error())
+		if err != nil {
+			panic(err)
+		}
+	}
+	glg.fatalExit(1)
+}
+
+// PanicCtx outputs Panic log with fields from ctx and panics with the message
+func (g *Glg) PanicCtx(ctx context.Context, val ...interface{}) {
+	g.panicOutput(g.contextFields(ctx), g.blankFormat(len(val)), val...)
+}
+
+// PanicfCtx outputs formatted Panic log with fields from ctx and panics with the message
+func (g *Glg) PanicfCtx(ctx context.Context, format string, val ...interface{}) {
+	g.panicOutput(g.contextFields(ctx), format, val...)
+}
+
+// PanicFuncCtx outputs Panic log returned from the function with fields from ctx and panics with it
+func (g *Glg) PanicFuncCtx(ctx context.Context, f func() string) {
+	g.panicOutput(g.contextFields(ctx), "%s", f())
+}
+
+// PanicCtx outputs Panic log with fields from ctx and panics with the message
+func PanicCtx(ctx context.Context, val ...interface{}) {
+	glg.panicOutput(glg.contextFields(ctx), glg.blankFormat(len(val)), val...)
+}
+
+// PanicfCtx outputs formatted Panic log with fields from ctx and panics with the message
+func PanicfCtx(ctx context.Context, format string, val ...interface{}) {
+	glg.panicOutput(glg.contextFields(ctx), format, val...)
+}
+
+// PanicFuncCtx outputs Panic log returned from the function with fields from ctx and panics with it
+func PanicFuncCtx(ctx context.Context, f func() string) {
+	glg.panicOutput(glg.contextFields(ctx), "%s", f())
+}
@@ -0,0 +1,117 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package glg
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func requestIDExtractor(ctx context.Context) []Field {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return []Field{{Key: "request_id", Value: id}}
+	}
+	return nil
+}
+
+func TestContextWithFields(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), "tenant", "a")
+	child := ContextWithFields(ctx, "user", "b")
+	if got := FieldsFromContext(ctx); len(got) != 1 || got[0].Key != "tenant" {
+		t.Errorf("FieldsFromContext() = %v", got)
+	}
+	if got := FieldsFromContext(child); len(got) != 2 || got[1].Key != "user" {
+		t.Errorf("FieldsFromContext() = %v", got)
+	}
+	if got := FieldsFromContext(nil); got != nil {
+		t.Errorf("FieldsFromContext(nil) = %v", got)
+	}
+}
+
+func TestGlg_Ctx(t *testing.T) {
+	ctx := ContextWithFields(context.WithValue(context.Background(), requestIDKey{}, "req-1"), "tenant", "a")
+	tests := []struct {
+		name  string
+		level LEVEL
+		log   func(g *Glg) error
+	}{
+		{name: "LogCtx", level: LOG, log: func(g *Glg) error { return g.LogCtx(ctx, "sample") }},
+		{name: "InfofCtx", level: INFO, log: func(g *Glg) error { return g.InfofCtx(ctx, "%s", "sample") }},
+		{name: "SuccessFuncCtx", level: OK, log: func(g *Glg) error { return g.SuccessFuncCtx(ctx, func() string { return "sample" }) }},
+		{name: "DebugCtx", level: DEBG, log: func(g *Glg) error { return g.DebugCtx(ctx, "sample") }},
+		{name: "WarnCtx", level: WARN, log: func(g *Glg) error { return g.WarnCtx(ctx, "sample") }},
+		{name: "TraceCtx", level: TRACE, log: func(g *Glg) error { return g.TraceCtx(ctx, "sample") }},
+		{name: "PrintlnCtx", level: PRINT, log: func(g *Glg) error { return g.PrintlnCtx(ctx, "sample") }},
+		{name: "ErrorCtx", level: ERR, log: func(g *Glg) error { return g.ErrorCtx(ctx, "sample") }},
+		{name: "FailfCtx", level: FAIL, log: func(g *Glg) error { return g.FailfCtx(ctx, "%s", "sample") }},
+		{name: "CustomLogCtx", level: INFO, log: func(g *Glg) error { return g.CustomLogCtx(ctx, "INFO", "sample") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineShort).
+				AddContextExtractor(requestIDExtractor).With("app", "glg")
+			if err := tt.log(g); err != nil {
+				t.Errorf("Glg.%s() unexpected error: %v", tt.name, err)
+			}
+			got := buf.String()
+			if !strings.Contains(got, "["+tt.level.String()+"]") {
+				t.Errorf("Glg.%s() level = %v, want %v", tt.name, got, tt.level)
+			}
+			if !strings.Contains(got, "(context_test.go:") {
+				t.Errorf("Glg.%s() line trace = %v", tt.name, got)
+			}
+			if !strings.HasSuffix(got, "sample\tapp=glg\ttenant=a\trequest_id=req-1\n") {
+				t.Errorf("Glg.%s() = %v", tt.name, got)
+			}
+		})
+	}
+}
+
+func TestGlg_FatalCtx(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf)
+	err := testExit(1, func() {
+		g.FatalCtx(ContextWithFields(context.Background(), "k", "v"), "fatal")
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.HasSuffix(buf.String(), "fatal\tk=v\n") {
+		t.Errorf("Glg.FatalCtx() = %v", buf.String())
+	}
+}
+
+func TestInfoCtx(t *testing.T) {
+	buf := new(bytes.Buffer)
+	Get().SetMode(WRITER).SetWriter(buf)
+	if err := InfoCtx(ContextWithFields(context.Background(), "k", "v"), "sample"); err != nil {
+		t.Error(err)
+	}
+	if !strings.HasSuffix(buf.String(), "sample\tk=v\n") {
+		t.Errorf("InfoCtx() = %v", buf.String())
+	}
+}
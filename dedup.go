@@ -0,0 +1,172 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// deduper collapses consecutive identical entries of a level
+type deduper struct {
+	mu     sync.Mutex
+	window time.Duration
+	last   string
+	start  time.Time
+	count  uint64
+	level  LEVEL
+	gen    uint64
+	timer  *time.Timer
+	report func(lv LEVEL, repeated uint64)
+}
+
+// SetDedup collapses consecutive identical entries of every level logged within window
+// into one entry followed by "last message repeated N times", non positive window disables it.
+func (g *Glg) SetDedup(window time.Duration) *Glg {
+	g.updateLoggers(func(l *logger) {
+		l.dedup.flush()
+		l.dedup = newDeduper(window, g.reportRepeats)
+	})
+	return g
+}
+
+// SetLevelDedup collapses consecutive identical entries of the level logged within window
+// into one entry followed by "last message repeated N times", non positive window disables it.
+func (g *Glg) SetLevelDedup(lv LEVEL, window time.Duration) *Glg {
+	g.updateLogger(lv, func(l *logger) {
+		l.dedup.flush()
+		l.dedup = newDeduper(window, g.reportRepeats)
+	})
+	return g
+}
+
+func newDeduper(window time.Duration, report func(lv LEVEL, repeated uint64)) *deduper {
+	if window <= 0 {
+		return nil
+	}
+	return &deduper{
+		window: window,
+		report: report,
+	}
+}
+
+// check reports whether the entry logged by the caller at pc at now is logged.
+// The entries are repeats when they have the same message and fields and are logged from the same caller.
+// repeated is the number of suppressed repeats of the previous entry to be reported before it.
+// The repeats are also reported by report when the window since the first entry has passed or flush is called.
+func (d *deduper) check(e *Entry, pc uintptr, now time.Time) (repeated uint64, ok bool) {
+	b := new(bytes.Buffer)
+	b.WriteString(strconv.FormatUint(uint64(pc), 16))
+	b.WriteByte(0)
+	b.WriteString(e.message())
+	appendTextFields(b, e.Fields)
+	key := b.String()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if key == d.last && now.Sub(d.start) < d.window {
+		d.count++
+		if d.timer == nil {
+			d.level = e.Level
+			gen := d.gen
+			d.timer = time.AfterFunc(d.window-now.Sub(d.start), func() {
+				d.expire(gen)
+			})
+		}
+		return 0, false
+	}
+	repeated = d.take()
+	d.last = key
+	d.start = now
+	return repeated, true
+}
+
+// take returns the pending repeats and stops the timer reporting them, d.mu must be held
+func (d *deduper) take() (repeated uint64) {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+		d.gen++
+	}
+	repeated, d.count = d.count, 0
+	return repeated
+}
+
+// expire reports the pending repeats when the window has passed and they are not taken yet
+func (d *deduper) expire(gen uint64) {
+	d.mu.Lock()
+	if gen != d.gen {
+		d.mu.Unlock()
+		return
+	}
+	d.release()
+}
+
+// flush reports the pending repeats, the next entry is logged even if it is identical
+func (d *deduper) flush() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.release()
+}
+
+// release resets the pending entry, unlocks d.mu and reports the repeats of it
+func (d *deduper) release() {
+	repeated := d.take()
+	d.last = ""
+	lv := d.level
+	d.mu.Unlock()
+	if repeated != 0 {
+		d.report(lv, repeated)
+	}
+}
+
+// reportRepeats writes the summary of repeated entries to the destinations of lv
+func (g *Glg) reportRepeats(lv LEVEL, repeated uint64) {
+	log, ok := g.logger.Load(lv)
+	if !ok {
+		return
+	}
+	g.handleError(g.emitRepeats(log, lv, g.entryTime(log), repeated))
+}
+
+// emitRepeats writes the summary of repeated entries logged at now to the destinations of log
+func (g *Glg) emitRepeats(log *logger, lv LEVEL, now time.Time, repeated uint64) error {
+	e := newEntry(lv, log, now, nil, "last message repeated %d times", []interface{}{repeated})
+	defer releaseEntry(e)
+	e.repeat = repeated
+	return g.emit(log, e)
+}
+
+// repeatFields returns fields of the repeated message summary encoded by enc,
+// the text format has the count only in the message
+func repeatFields(enc Encoder, repeated uint64) []Field {
+	if _, ok := enc.(textEncoder); ok {
+		return nil
+	}
+	return []Field{{Key: "repeat", Value: repeated}}
+}
@@ -0,0 +1,184 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestGlg_SetLevelDedup(t *testing.T) {
+	tests := []struct {
+		name string
+		logs []string
+		want []string
+	}{
+		{
+			name: "repeated then different",
+			logs: []string{"a", "a", "a", "b"},
+			want: []string{"a", "last message repeated 2 times", "b"},
+		},
+		{
+			name: "no repeats",
+			logs: []string{"a", "b", "a"},
+			want: []string{"a", "b", "a"},
+		},
+		{
+			name: "pending repeats are reported by sync",
+			logs: []string{"a", "a"},
+			want: []string{"a", "last message repeated 1 times"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLevelDedup(INFO, time.Minute)
+			for _, msg := range tt.logs {
+				if err := g.Info(msg); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := g.Sync(); err != nil {
+				t.Error(err)
+			}
+			var got []string
+			for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+				got = append(got, g.RawString([]byte(line+"\n")))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Glg.SetLevelDedup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_SetLevelDedupWindow(t *testing.T) {
+	buf := new(bytes.Buffer)
+	clock := &fakeClock{t: time.Now()}
+	g := New().SetMode(WRITER).SetWriter(buf).SetClock(clock.now).SetLevelDedup(WARN, time.Minute)
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			clock.t = clock.t.Add(time.Minute)
+		}
+		g.Warn("storm")
+	}
+	got := buf.String()
+	if strings.Count(got, "storm") != 2 || !strings.Contains(got, "last message repeated 1 times") {
+		t.Errorf("Glg.SetLevelDedup() = %v", got)
+	}
+}
+
+func TestGlg_SetLevelDedupTimer(t *testing.T) {
+	w := new(syncWriter)
+	g := New().SetMode(WRITER).SetWriter(w).SetLevelDedup(INFO, 10*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		g.Info("storm")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(w.String(), "last message repeated 2 times") {
+		if time.Now().After(deadline) {
+			t.Fatalf("pending repeats are not reported after the window: %v", w.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := g.Sync(); err != nil {
+		t.Error(err)
+	}
+	if got := strings.Count(w.String(), "last message repeated"); got != 1 {
+		t.Errorf("pending repeats are reported %d times: %v", got, w.String())
+	}
+}
+
+func TestGlg_SetLevelDedupCaller(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLevelDedup(INFO, time.Minute)
+	a := func() { g.Info("same") }
+	b := func() { g.Info("same") }
+	for _, f := range []func(){a, b, a, a, b} {
+		f()
+	}
+	got := buf.String()
+	if n := strings.Count(got, "same"); n != 4 {
+		t.Errorf("entries from different callers are collapsed: %v", got)
+	}
+	if !strings.Contains(got, "last message repeated 1 times") {
+		t.Errorf("repeats from the same caller are not collapsed: %v", got)
+	}
+}
+
+func TestGlg_SetDedupFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetDedup(time.Minute)
+	g.With("k", 1).Error("same")
+	g.With("k", 2).Error("same")
+	if got := strings.Count(buf.String(), "same"); got != 2 {
+		t.Errorf("entries with different fields are collapsed: %v", buf.String())
+	}
+	g.SetDedup(0)
+	if l, _ := g.logger.Load(ERR); l.dedup != nil {
+		t.Error("Glg.SetDedup() did not disable dedup")
+	}
+}
+
+func TestGlg_SetLevelDedupJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableJSON().SetLevelDedup(INFO, time.Minute)
+	for _, msg := range []string{"a", "a", "a", "b"} {
+		g.Info(msg)
+	}
+	dec := json.NewDecoder(buf)
+	var got []map[string]interface{}
+	for dec.More() {
+		var m map[string]interface{}
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, m)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Glg.SetLevelDedup() json entries = %v", got)
+	}
+	if got[1]["repeat"] != float64(2) || got[1]["detail"] != "last message repeated 2 times" {
+		t.Errorf("Glg.SetLevelDedup() json summary = %v", got[1])
+	}
+	if _, ok := got[2]["repeat"]; ok || got[2]["detail"] != "b" {
+		t.Errorf("Glg.SetLevelDedup() json entry = %v", got[2])
+	}
+}
+
+func TestGlg_SetLevelDedupWriterEncoder(t *testing.T) {
+	text, js := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(text).AddWriter(js).SetWriterEncoder(js, JSONEncoder).SetLevelDedup(INFO, time.Minute)
+	for _, msg := range []string{"a", "a", "b"} {
+		g.Info(msg)
+	}
+	if got := text.String(); !strings.Contains(got, "last message repeated 1 times") || strings.Contains(got, "repeat=") {
+		t.Errorf("Glg.SetLevelDedup() text = %v", got)
+	}
+	if got := js.String(); !strings.Contains(got, `"repeat":1`) {
+		t.Errorf("Glg.SetLevelDedup() json = %v", got)
+	}
+}
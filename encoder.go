@@ -0,0 +1,372 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// Entry is log entry passed to Encoder
+type Entry struct {
+	// Time is the time of the entry in the time location of the level, it is zero when the timestamp is disabled
+	Time time.Time
+	// Timestamp is Time formatted by the time format of the level, it is empty when the timestamp is disabled
+	Timestamp string
+	Level     LEVEL
+	Tag       string
+	// Caller is the line trace, it is empty when the line trace is disabled
+	Caller string
+	// Message is the formatted message
+	Message string
+	// Values are the logged values when the entry has no format string, such as Info(val...) in JSON mode
+	Values []interface{}
+	Fields []Field
+	// PC is the program counter of the caller which Glg.EncodeEntry formats to Caller when Caller is empty,
+	// it is zero in the entries passed to encoders by glg.
+	PC uintptr
+
+	// format and args are the logged values, the built-in encoders format them
+	// without building Message unless another encoder needs it
+	format    string
+	args      []interface{}
+	formatted bool
+	// repeat is the count of the repeated message summary, emit adds it as a field unless the format is text
+	repeat uint64
+}
+
+// destination is a writer of the level and its encoder resolved when the logger is stored
+type destination struct {
+	w   io.Writer
+	enc Encoder
+	// color is set for the std output colored by the level color
+	color bool
+	// reuse is set when enc is the encoder of the previous destination, so its encoded line is written again
+	reuse bool
+	// custom is set when enc is not a built-in encoder, Message is built before calling it
+	custom bool
+}
+
+// message returns Message, it is formatted from the logged values on first use
+func (e *Entry) message() string {
+	if !e.formatted {
+		if e.format != "" || e.args != nil {
+			e.Message = formatMessage(e.format, e.args...)
+		}
+		e.formatted = true
+	}
+	return e.Message
+}
+
+// formatMessage returns the message of the entry logged with format and val,
+// val are separated by spaces when format is empty like the default text format.
+// It is shared by the entries, hooks and the panic values so that they have the same message.
+func formatMessage(format string, val ...interface{}) string {
+	if format != "" {
+		return fmt.Sprintf(format, val...)
+	}
+	return strings.TrimSuffix(fmt.Sprintln(val...), rc)
+}
+
+// appendMessage writes the message to b, formatting it straight into b when Message is not built yet
+func (e *Entry) appendMessage(b *bytes.Buffer) {
+	if !e.formatted && e.format != "" {
+		fmt.Fprintf(b, e.format, e.args...)
+		return
+	}
+	b.WriteString(e.message())
+}
+
+// Encoder encodes log entry to a line written to the destinations
+type Encoder interface {
+	// EncodeEntry appends encoded e to buf, the encoded entry should end with a newline.
+	// e is reused by glg after EncodeEntry returns, so it must not be retained.
+	EncodeEntry(buf *bytes.Buffer, e *Entry) error
+}
+
+// entryPool is pool of the entries built by emit
+var entryPool = sync.Pool{
+	New: func() interface{} {
+		return new(Entry)
+	},
+}
+
+// EncoderFunc is function type of Encoder
+type EncoderFunc func(buf *bytes.Buffer, e *Entry) error
+
+// EncodeEntry calls f(buf, e)
+func (f EncoderFunc) EncodeEntry(buf *bytes.Buffer, e *Entry) error {
+	return f(buf, e)
+}
+
+type (
+	textEncoder   struct{}
+	jsonEncoder   struct{}
+	logfmtEncoder struct{}
+)
+
+var (
+	// TextEncoder is default tab separated format such as "2006-01-02 15:04:05\t[INFO]:\tmessage\tkey=value".
+	// The entries are colored when the destination is std output in color mode.
+	TextEncoder Encoder = textEncoder{}
+	// JSONEncoder is JSON format of JSONFormat, it is used by EnableJSON
+	JSONEncoder Encoder = jsonEncoder{}
+	// LogfmtEncoder is logfmt format such as "ts=... level=INFO caller=... msg=... key=value",
+	// time.RFC3339Nano time format set by SetTimeFormat is recommended for logfmt
+	LogfmtEncoder Encoder = logfmtEncoder{}
+)
+
+// SetEncoder sets encoder of all levels, nil resets to text or JSON format selected by EnableJSON.
+// Encoders set by SetLevelEncoder and SetWriterEncoder take precedence.
+func (g *Glg) SetEncoder(enc Encoder) *Glg {
+	if enc == nil {
+		g.encoder.Store(nil)
+	} else {
+		g.encoder.Store(&enc)
+		g.enableJSON.Store(sameEncoder(enc, JSONEncoder))
+	}
+	g.updateLoggers(func(*logger) {})
+	return g
+}
+
+// SetLevelEncoder sets encoder of the level, nil resets to the encoder of all levels
+func (g *Glg) SetLevelEncoder(lv LEVEL, enc Encoder) *Glg {
+	g.updateLogger(lv, func(l *logger) {
+		l.encoder = enc
+	})
+	return g
+}
+
+// SetWriterEncoder sets encoder of the entries written to w by any level, nil resets it.
+// w must be comparable such as *os.File.
+// Writers implementing Encoder such as SyslogWriter encode their entries by themselves unless SetWriterEncoder is called.
+func (g *Glg) SetWriterEncoder(w io.Writer, enc Encoder) *Glg {
+	if w == nil || !reflect.TypeOf(w).Comparable() {
+		g.handleError(fmt.Errorf("writer %T cannot have encoder", w))
+		return g
+	}
+	if enc == nil {
+		g.writerEncoders.Delete(w)
+	} else {
+		g.writerEncoders.Store(w, enc)
+	}
+	g.updateLoggers(func(*logger) {})
+	return g
+}
+
+// SetEncoder sets encoder of all levels
+func SetEncoder(enc Encoder) *Glg {
+	return glg.SetEncoder(enc)
+}
+
+// updateDestinations resolves the writers of l for its write mode and their encoders,
+// it is called before l is stored so that emit does not look them up for every entry.
+func (g *Glg) updateDestinations(l *logger) {
+	var std, writer io.Writer
+	switch l.writeMode {
+	case writeColorStd, writeStd:
+		std = l.std
+	case writeWriter:
+		writer = l.writer
+	case writeColorBoth, writeBoth:
+		std, writer = l.std, l.writer
+	}
+	ws := make([]io.Writer, 0, 2)
+	if std != nil {
+		ws = append(ws, std)
+	}
+	if mw, ok := writer.(multiWriter); ok {
+		ws = append(ws, mw...)
+	} else if writer != nil {
+		ws = append(ws, writer)
+	}
+	dsts := make([]destination, 0, len(ws))
+	for i, w := range ws {
+		if w == nil {
+			continue
+		}
+		d := destination{
+			w:   w,
+			enc: g.encoderFor(l, w),
+		}
+		d.color = i == 0 && std != nil && l.isColor && sameEncoder(d.enc, TextEncoder)
+		d.reuse = len(dsts) != 0 && sameEncoder(dsts[len(dsts)-1].enc, d.enc)
+		switch d.enc.(type) {
+		case textEncoder, jsonEncoder, logfmtEncoder:
+		default:
+			d.custom = true
+		}
+		dsts = append(dsts, d)
+	}
+	l.dsts = dsts
+}
+
+// encoderFor returns encoder of the entry written to w by log.
+// The precedence is the writer encoder, w itself, the level encoder and the encoder of all levels.
+func (g *Glg) encoderFor(log *logger, w io.Writer) Encoder {
+	if reflect.TypeOf(w).Comparable() {
+		if enc, ok := g.writerEncoders.Load(w); ok {
+			return enc.(Encoder)
+		}
+	}
+	if enc, ok := w.(Encoder); ok {
+		return enc
+	}
+	return g.levelEncoder(log)
+}
+
+// levelEncoder returns the encoder of the level without the writer encoders
+func (g *Glg) levelEncoder(log *logger) Encoder {
+	if log.encoder != nil {
+		return log.encoder
+	}
+	if enc := g.encoder.Load(); enc != nil {
+		return *enc
+	}
+	if g.enableJSON.Load() {
+		return JSONEncoder
+	}
+	return TextEncoder
+}
+
+// EncodeEntry encodes e by the encoder, time format and line trace mode of e.Level without color,
+// so that the entries of other logging libraries are formatted like glg entries.
+// Tag is set from the level when it is empty, Timestamp is formatted from Time when it is empty
+// and Caller is formatted from PC when it is empty.
+func (g *Glg) EncodeEntry(b *bytes.Buffer, e *Entry) error {
+	log, ok := g.logger.Load(e.Level)
+	if !ok {
+		return fmt.Errorf("error:\tLog Level %d Not Found", e.Level)
+	}
+	if e.Tag == "" {
+		e.Tag = log.tag
+	}
+	switch {
+	case log.disableTimestamp:
+		e.Time, e.Timestamp = time.Time{}, ""
+	case e.Timestamp == "" && !e.Time.IsZero():
+		e.Timestamp = log.timestamp.format(e.Time)
+		e.Time = e.Time.In(log.timestamp.loc)
+	}
+	switch {
+	case log.traceMode&(TraceLineLong|TraceLineShort) == 0:
+		e.Caller = ""
+	case e.Caller == "" && e.PC != 0:
+		file, line, ok := caller(-1, e.PC)
+		e.Caller = traceString(log.traceMode, file, line, ok)
+	}
+	return g.levelEncoder(log).EncodeEntry(b, e)
+}
+
+// sameEncoder reports whether a and b are the same encoder without panicking on non comparable encoders
+func sameEncoder(a, b Encoder) bool {
+	ta := reflect.TypeOf(a)
+	return ta == reflect.TypeOf(b) && ta.Comparable() && a == b
+}
+
+// EncodeEntry encodes e in the default text format
+func (textEncoder) EncodeEntry(b *bytes.Buffer, e *Entry) error {
+	if len(e.Timestamp) != 0 {
+		b.WriteString(e.Timestamp)
+		b.WriteString(lsep)
+	} else {
+		b.WriteByte('[')
+	}
+	b.WriteString(e.Tag)
+	b.WriteString(sep)
+	if len(e.Caller) != 0 {
+		b.WriteString("(" + e.Caller + "):\t")
+	}
+	e.appendMessage(b)
+	if len(e.Fields) != 0 {
+		appendTextFields(b, e.Fields)
+	}
+	b.WriteString(rc)
+	return nil
+}
+
+// EncodeEntry encodes e as JSONFormat
+func (jsonEncoder) EncodeEntry(b *bytes.Buffer, e *Entry) error {
+	var detail interface{}
+	switch {
+	case e.Values == nil:
+		detail = e.message()
+	case len(e.Values) > 1:
+		detail = e.Values
+	case len(e.Values) == 1:
+		detail = e.Values[0]
+	}
+	j := jsonFormat{
+		Date:   e.Timestamp,
+		Level:  e.Tag,
+		File:   e.Caller,
+		Detail: detail,
+	}
+	if len(e.Fields) == 0 {
+		return json.NewEncoder(b).Encode(j)
+	}
+	obj, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	obj, err = appendJSONFields(obj[:len(obj)-1], e.Fields)
+	if err != nil {
+		return err
+	}
+	b.Write(obj)
+	b.WriteString(rc)
+	return nil
+}
+
+// EncodeEntry encodes e in logfmt format
+func (logfmtEncoder) EncodeEntry(b *bytes.Buffer, e *Entry) error {
+	if len(e.Timestamp) != 0 {
+		b.WriteString("ts=")
+		appendLogfmtValue(b, e.Timestamp)
+		b.WriteByte(' ')
+	}
+	b.WriteString("level=")
+	appendLogfmtValue(b, e.Tag)
+	if len(e.Caller) != 0 {
+		b.WriteString(" caller=")
+		appendLogfmtValue(b, e.Caller)
+	}
+	b.WriteString(" msg=")
+	appendLogfmtValue(b, e.message())
+	for _, f := range e.Fields {
+		b.WriteByte(' ')
+		appendLogfmtKey(b, f.Key)
+		b.WriteByte('=')
+		appendLogfmtValue(b, fieldValueString(f.Value))
+	}
+	b.WriteString(rc)
+	return nil
+}
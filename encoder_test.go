@@ -0,0 +1,258 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+)
+
+type upperEncoder struct{}
+
+func (upperEncoder) EncodeEntry(b *bytes.Buffer, e *Entry) error {
+	b.WriteString(e.Tag + "|" + e.Message)
+	for _, f := range e.Fields {
+		b.WriteString("|" + f.Key + "=" + fieldValueString(f.Value))
+	}
+	b.WriteString(rc)
+	return nil
+}
+
+func TestGlg_SetEncoder(t *testing.T) {
+	errEncode := errors.New("encode error")
+	tests := []struct {
+		name    string
+		setup   func(g *Glg, std, w *bytes.Buffer) *Glg
+		log     func(g *Glg) error
+		wantStd string
+		wantW   string
+		wantErr error
+	}{
+		{
+			name:    "text encoder is default",
+			setup:   func(g *Glg, _, _ *bytes.Buffer) *Glg { return g },
+			log:     func(g *Glg) error { return g.With("k", "v 1").Info("a", "b") },
+			wantStd: "[INFO]:\ta b\tk=\"v 1\"\n",
+			wantW:   "[INFO]:\ta b\tk=\"v 1\"\n",
+		},
+		{
+			name:    "global encoder",
+			setup:   func(g *Glg, _, _ *bytes.Buffer) *Glg { return g.SetEncoder(upperEncoder{}) },
+			log:     func(g *Glg) error { return g.With("k", 1).Warnf("%d%%", 5) },
+			wantStd: "WARN|5%|k=1\n",
+			wantW:   "WARN|5%|k=1\n",
+		},
+		{
+			name:    "json encoder keeps raw values",
+			setup:   func(g *Glg, _, _ *bytes.Buffer) *Glg { return g.SetEncoder(JSONEncoder) },
+			log:     func(g *Glg) error { return g.Info(1, "b") },
+			wantStd: `{"level":"INFO","detail":[1,"b"]}` + "\n",
+			wantW:   `{"level":"INFO","detail":[1,"b"]}` + "\n",
+		},
+		{
+			name: "level encoder takes precedence over global encoder",
+			setup: func(g *Glg, _, _ *bytes.Buffer) *Glg {
+				return g.EnableJSON().SetLevelEncoder(INFO, upperEncoder{})
+			},
+			log:     func(g *Glg) error { return g.Info("a", "b") },
+			wantStd: "INFO|a b\n",
+			wantW:   "INFO|a b\n",
+		},
+		{
+			name: "writer encoder takes precedence over level encoder",
+			setup: func(g *Glg, _, w *bytes.Buffer) *Glg {
+				return g.SetLevelEncoder(INFO, LogfmtEncoder).SetWriterEncoder(w, JSONEncoder)
+			},
+			log:     func(g *Glg) error { return g.Info("msg") },
+			wantStd: "level=INFO msg=msg\n",
+			wantW:   `{"level":"INFO","detail":"msg"}` + "\n",
+		},
+		{
+			name: "reset writer encoder",
+			setup: func(g *Glg, _, w *bytes.Buffer) *Glg {
+				return g.SetWriterEncoder(w, JSONEncoder).SetWriterEncoder(w, nil)
+			},
+			log:     func(g *Glg) error { return g.Info("msg") },
+			wantStd: "[INFO]:\tmsg\n",
+			wantW:   "[INFO]:\tmsg\n",
+		},
+		{
+			name: "encoder func",
+			setup: func(g *Glg, _, _ *bytes.Buffer) *Glg {
+				return g.SetEncoder(EncoderFunc(func(b *bytes.Buffer, e *Entry) error {
+					b.WriteString(e.Message + rc)
+					return nil
+				}))
+			},
+			log:     func(g *Glg) error { return g.Info("msg") },
+			wantStd: "msg\n",
+			wantW:   "msg\n",
+		},
+		{
+			name: "encoder error",
+			setup: func(g *Glg, _, _ *bytes.Buffer) *Glg {
+				return g.SetEncoder(EncoderFunc(func(*bytes.Buffer, *Entry) error {
+					return errEncode
+				}))
+			},
+			log:     func(g *Glg) error { return g.Info("msg") },
+			wantErr: errEncode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			std, w := new(bytes.Buffer), new(bytes.Buffer)
+			g := New().SetMode(BOTH).DisableColor().DisableTimestamp().SetLineTraceMode(TraceLineNone).SetWriter(w)
+			g.SetLevelWriter(INFO, w).SetLevelWriter(WARN, w)
+			g.updateLoggers(func(l *logger) {
+				l.std = std
+				l.updateMode()
+			})
+			g = tt.setup(g, std, w)
+			if err := tt.log(g); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got := std.String(); got != tt.wantStd {
+				t.Errorf("std output = %q, want %q", got, tt.wantStd)
+			}
+			if got := w.String(); got != tt.wantW {
+				t.Errorf("writer output = %q, want %q", got, tt.wantW)
+			}
+		})
+	}
+}
+
+func TestGlg_EncoderColor(t *testing.T) {
+	std, w := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetMode(BOTH).DisableTimestamp().SetLineTraceMode(TraceLineNone).SetLevelWriter(INFO, w)
+	g.updateLogger(INFO, func(l *logger) {
+		l.std = std
+		l.updateMode()
+	})
+
+	if err := g.Info("msg"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := std.String(), Green("[INFO]:\tmsg")+"\n"; got != want {
+		t.Errorf("text std output = %q, want %q", got, want)
+	}
+	if got, want := w.String(), "[INFO]:\tmsg\n"; got != want {
+		t.Errorf("text writer output = %q, want %q", got, want)
+	}
+
+	std.Reset()
+	if err := g.SetLevelEncoder(INFO, LogfmtEncoder).Info("msg"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := std.String(), "level=INFO msg=msg\n"; got != want {
+		t.Errorf("logfmt std output = %q, want %q", got, want)
+	}
+}
+
+func TestGlg_EncoderAsync(t *testing.T) {
+	w, f := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).
+		SetLevelWriter(INFO, w).SetLevelWriter(ERR, f).SetWriterEncoder(f, LogfmtEncoder).
+		EnableAsync(16, OverflowBlock)
+	defer g.Close()
+
+	g.Info("info")
+	g.Error("error")
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.String(), "[INFO]:\tinfo\n"; got != want {
+		t.Errorf("writer output = %q, want %q", got, want)
+	}
+	if got, want := f.String(), "level=ERR msg=error\n"; got != want {
+		t.Errorf("encoded writer output = %q, want %q", got, want)
+	}
+}
+
+func TestGlg_EncodeEntry(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	pc, _, _, _ := runtime.Caller(0)
+	tests := []struct {
+		name  string
+		setup func(g *Glg) *Glg
+		entry Entry
+		want  string
+	}{
+		{
+			name:  "text",
+			setup: func(g *Glg) *Glg { return g },
+			entry: Entry{Time: now, Level: WARN, Message: "msg", Fields: []Field{{Key: "k", Value: 1}}},
+			want:  `^2024-01-02 03:04:05\t\[WARN\]:\tmsg\tk=1\n$`,
+		},
+		{
+			name:  "caller from pc",
+			setup: func(g *Glg) *Glg { return g.DisableTimestamp().SetLevelLineTraceMode(INFO, TraceLineShort) },
+			entry: Entry{Time: now, Level: INFO, Message: "msg", PC: pc + 1},
+			want:  `^\[INFO\]:\t\(encoder_test\.go:\d+\):\tmsg\n$`,
+		},
+		{
+			name:  "level encoder and tag",
+			setup: func(g *Glg) *Glg { return g.SetLevelEncoder(ERR, upperEncoder{}).SetPrefix(ERR, "E") },
+			entry: Entry{Time: now, Level: ERR, Message: "msg", Caller: "x.go:1"},
+			want:  `^E\|msg\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.setup(New().SetTimeLocation(time.UTC).SetLineTraceMode(TraceLineNone))
+			b := new(bytes.Buffer)
+			e := tt.entry
+			if err := g.EncodeEntry(b, &e); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); !regexp.MustCompile(tt.want).MatchString(got) {
+				t.Errorf("Glg.EncodeEntry() = %q, want match %s", got, tt.want)
+			}
+		})
+	}
+	if err := New().EncodeEntry(new(bytes.Buffer), &Entry{Level: UNKNOWN}); err == nil {
+		t.Error("Glg.EncodeEntry() of unknown level returns nil error")
+	}
+}
+
+func TestGlg_Emit(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineShort).With("base", 1)
+	pc, _, _, _ := runtime.Caller(0)
+	if err := g.Emit(WARN, pc+1, []Field{{Key: "k", Value: "v"}}, "msg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Emit(INFO, 0, nil, "unknown caller"); err != nil {
+		t.Fatal(err)
+	}
+	want := `^\[WARN\]:\t\(encoder_test\.go:\d+\):\tmsg\tbase=1\tk=v\n\[INFO\]:\t\(\?\?\?:0\):\tunknown caller\tbase=1\n$`
+	if got := buf.String(); !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("output = %q, want match %s", got, want)
+	}
+}
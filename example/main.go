@@ -97,10 +97,17 @@ func main() {
 
 	errlog := glg.FileWriter("/tmp/error.log", 0o666)
 	rotate := NewRotateWriter(os.Stdout, time.Second*10, bytes.NewBuffer(make([]byte, 0, 4096)))
+	// daily rotated file, rotated also by 10MB and keeps 7 gzip compressed backups
+	faillog := glg.NewRotateFile("/tmp/fail-%Y%m%d.log", 0o666).
+		SetRotateInterval(glg.RotateDaily).
+		SetMaxSize(10 << 20).
+		SetMaxBackups(7).
+		EnableCompress()
 
 	defer infolog.Close()
 	defer errlog.Close()
 	defer rotate.Close()
+	defer faillog.Close()
 
 	glg.Get().
 		SetMode(glg.BOTH). // default is STD
@@ -123,7 +130,8 @@ func main() {
 		SetLineTraceMode(glg.TraceLineNone).
 		AddLevelWriter(glg.INFO, infolog). // add info log file destination
 		AddLevelWriter(glg.ERR, errlog).   // add error log file destination
-		AddLevelWriter(glg.WARN, rotate)   // add error log file destination
+		AddLevelWriter(glg.WARN, rotate).  // add error log file destination
+		AddLevelWriter(glg.FAIL, faillog)  // add rotating fail log file destination
 
 	glg.Info("info")
 	glg.Infof("%s : %s", "info", "formatted")
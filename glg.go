@@ -47,13 +47,25 @@ import (
 
 // Glg is glg base struct
 type Glg struct {
-	bs           *uint64
-	logger       loggers
-	levelCounter *uint32
-	levelMap     levelMap
-	buffer       sync.Pool
-	callerDepth  int
-	enableJSON   bool
+	bs             *uint64
+	logger         *loggers
+	levelCounter   *uint32
+	levelMu        *sync.Mutex // serializes level registration and logger updates
+	levelMap       *levelMap
+	buffer         *sync.Pool
+	depth          *atomic.Int64 // line trace caller depth
+	enableJSON     *atomic.Bool
+	extractors     *atomic.Pointer[[]ContextExtractor]
+	async          *atomic.Pointer[asyncPipeline]
+	asyncDropped   *uint64 // entries dropped by all of the async pipelines
+	hooks          *atomic.Pointer[[]levelHook]
+	exitHandlers   *atomic.Pointer[[]func()]
+	errHandler     *atomic.Pointer[func(error)]
+	timeSetting    *atomic.Pointer[timeSetting]
+	clock          *atomic.Pointer[func() time.Time]
+	encoder        *atomic.Pointer[Encoder]
+	writerEncoders *sync.Map
+	fields         []Field
 }
 
 // JSONFormat is json object structure for logging
@@ -62,6 +74,13 @@ type JSONFormat struct {
 	Level  string      `json:"level,omitempty"`
 	File   string      `json:"file,omitempty"`
 	Detail interface{} `json:"detail,omitempty"`
+	Fields []Field     `json:"-"`
+}
+
+// Field is key/value pair attached to log entries
+type Field struct {
+	Key   string
+	Value interface{}
 }
 
 // MODE is logging mode (std only, writer only, std & writer)
@@ -77,6 +96,7 @@ type traceMode int64
 type logger struct {
 	tag              string
 	rawtag           []byte
+	severity         Severity
 	writer           io.Writer
 	std              io.Writer
 	color            func(string) string
@@ -86,6 +106,13 @@ type logger struct {
 	prevMode         MODE
 	writeMode        wMode
 	disableTimestamp bool
+	encoder          Encoder
+	timeSetting      timeSetting
+	timestamp        *timestamp
+	sampler          *sampler
+	dedup            *deduper
+	stats            *levelStats
+	dsts             []destination
 }
 
 const (
@@ -155,8 +182,16 @@ const (
 	TraceLineLong
 
 	DefaultCallerDepth = 2
+
+	// key for the value which has no paired key in With arguments
+	badKey = "!BADKEY"
 )
 
+// PANIC is panic log level, it is ordered between FAIL and FATAL by its severity.
+// It is declared out of the iota block above with the value below UNKNOWN,
+// so that the values of the other constants and custom levels are kept.
+const PANIC LEVEL = UNKNOWN - 1
+
 var (
 	glg  *Glg
 	once sync.Once
@@ -191,6 +226,34 @@ func (l LEVEL) String() string {
 		return "FAIL"
 	case FATAL:
 		return "FATAL"
+	case PANIC:
+		return "PANIC"
+	}
+	return ""
+}
+
+func (m MODE) String() string {
+	switch m {
+	case NONE:
+		return "NONE"
+	case STD:
+		return "STD"
+	case BOTH:
+		return "BOTH"
+	case WRITER:
+		return "WRITER"
+	}
+	return ""
+}
+
+func (t traceMode) String() string {
+	switch t {
+	case TraceLineNone:
+		return "none"
+	case TraceLineShort:
+		return "short"
+	case TraceLineLong:
+		return "long"
 	}
 	return ""
 }
@@ -213,17 +276,61 @@ func (l *logger) updateMode() *logger {
 	return l
 }
 
+// updateLogger replaces the logger of lv with its copy modified by f.
+// The loggers are never modified after being stored, so logging goroutines always see a consistent snapshot.
+func (g *Glg) updateLogger(lv LEVEL, f func(l *logger)) {
+	g.levelMu.Lock()
+	defer g.levelMu.Unlock()
+	l, ok := g.logger.Load(lv)
+	if !ok {
+		return
+	}
+	nl := *l
+	f(&nl)
+	g.updateDestinations(&nl)
+	g.logger.Store(lv, &nl)
+}
+
+// updateLoggers replaces the loggers of all levels with their copies modified by f
+func (g *Glg) updateLoggers(f func(l *logger)) {
+	g.levelMu.Lock()
+	defer g.levelMu.Unlock()
+	g.logger.Range(func(lev LEVEL, l *logger) bool {
+		nl := *l
+		f(&nl)
+		g.updateDestinations(&nl)
+		g.logger.Store(lev, &nl)
+		return true
+	})
+}
+
 // New returns plain glg instance
 func New() *Glg {
 	g := &Glg{
-		levelCounter: new(uint32),
-		callerDepth:  DefaultCallerDepth,
+		logger:         new(loggers),
+		levelCounter:   new(uint32),
+		levelMu:        new(sync.Mutex),
+		levelMap:       new(levelMap),
+		depth:          new(atomic.Int64),
+		enableJSON:     new(atomic.Bool),
+		extractors:     new(atomic.Pointer[[]ContextExtractor]),
+		async:          new(atomic.Pointer[asyncPipeline]),
+		asyncDropped:   new(uint64),
+		hooks:          new(atomic.Pointer[[]levelHook]),
+		exitHandlers:   new(atomic.Pointer[[]func()]),
+		errHandler:     new(atomic.Pointer[func(error)]),
+		timeSetting:    new(atomic.Pointer[timeSetting]),
+		clock:          new(atomic.Pointer[func() time.Time]),
+		encoder:        new(atomic.Pointer[Encoder]),
+		writerEncoders: new(sync.Map),
 	}
 	g.bs = new(uint64)
+	g.depth.Store(DefaultCallerDepth)
+	g.timeSetting.Store(&timeSetting{layout: DefaultTimeFormat, loc: time.Local})
 
 	atomic.StoreUint64(g.bs, uint64(len(timeFormat)+lsepl+sepl))
 
-	g.buffer = sync.Pool{
+	g.buffer = &sync.Pool{
 		New: func() interface{} {
 			return bytes.NewBuffer(make([]byte, 0, int(atomic.LoadUint64(g.bs))))
 		},
@@ -304,11 +411,26 @@ func New() *Glg {
 			mode:      STD,
 			traceMode: TraceLineLong,
 		},
+		PANIC: {
+			std:       os.Stderr,
+			color:     Red,
+			isColor:   true,
+			mode:      STD,
+			traceMode: TraceLineLong,
+		},
 	} {
 		log.tag = lev.String()
 		log.rawtag = []byte(lsep + log.tag + sep)
+		log.severity = SeverityOf(lev)
+		log.stats = new(levelStats)
+		g.levelMap.Store(log.tag, lev)
+		for _, alias := range defaultLevelAliases[lev] {
+			g.levelMap.Store(alias, lev)
+		}
 		log.prevMode = log.mode
 		log.updateMode()
+		g.updateTimestamp(log)
+		g.updateDestinations(log)
 		g.logger.Store(lev, log)
 	}
 
@@ -318,22 +440,67 @@ func New() *Glg {
 // Get returns singleton glg instance
 func Get() *Glg {
 	once.Do(func() {
-		fastime.SetFormat(timeFormat)
 		glg = New()
 	})
 	return glg
 }
 
 func (g *Glg) EnableJSON() *Glg {
-	g.enableJSON = true
+	g.enableJSON.Store(true)
+	g.encoder.Store(nil)
+	g.updateLoggers(func(*logger) {})
 	return g
 }
 
 func (g *Glg) DisableJSON() *Glg {
-	g.enableJSON = false
+	g.enableJSON.Store(false)
+	g.encoder.Store(nil)
+	g.updateLoggers(func(*logger) {})
 	return g
 }
 
+// With returns child glg instance which shares all configuration with g
+// and attaches given key/value pairs to every log entry.
+// Arguments are read as alternating keys and values, Field values are attached as is.
+func (g *Glg) With(kv ...interface{}) *Glg {
+	child := *g
+	child.fields = appendFields(g.fields[:len(g.fields):len(g.fields)], kv...)
+	return &child
+}
+
+// With returns child glg instance which attaches given key/value pairs to every log entry
+func With(kv ...interface{}) *Glg {
+	return glg.With(kv...)
+}
+
+// Fields returns key/value pairs attached to glg instance
+func (g *Glg) Fields() []Field {
+	return append([]Field(nil), g.fields...)
+}
+
+func appendFields(fields []Field, kv ...interface{}) []Field {
+	for i := 0; i < len(kv); i++ {
+		switch f := kv[i].(type) {
+		case Field:
+			fields = append(fields, f)
+		case []Field:
+			fields = append(fields, f...)
+		default:
+			if i+1 >= len(kv) {
+				fields = append(fields, Field{Key: badKey, Value: f})
+				continue
+			}
+			key, ok := f.(string)
+			if !ok {
+				key = fmt.Sprint(f)
+			}
+			fields = append(fields, Field{Key: key, Value: kv[i+1]})
+			i++
+		}
+	}
+	return fields
+}
+
 func (g *Glg) EnablePoolBuffer(size int) *Glg {
 	for range make([]struct{}, size) {
 		g.buffer.Put(g.buffer.Get().(*bytes.Buffer))
@@ -341,30 +508,29 @@ func (g *Glg) EnablePoolBuffer(size int) *Glg {
 	return g
 }
 
-// SetLevel sets glg global log level
+// SetLevel sets glg global log level, the levels less severe than lv are disabled
 func (g *Glg) SetLevel(lv LEVEL) *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
-		if lev < lv {
-			l.prevMode = l.mode
+	sev := g.Severity(lv)
+	g.updateLoggers(func(l *logger) {
+		if l.severity < sev {
+			if l.mode != NONE {
+				l.prevMode = l.mode
+			}
 			l.mode = NONE
 		} else {
 			l.mode = l.prevMode
 		}
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 	return g
 }
 
 // SetMode sets glg logging mode
 func (g *Glg) SetMode(mode MODE) *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		l.mode = mode
 		l.prevMode = mode
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -372,13 +538,11 @@ func (g *Glg) SetMode(mode MODE) *Glg {
 
 // SetLevelMode sets glg logging mode* per level
 func (g *Glg) SetLevelMode(level LEVEL, mode MODE) *Glg {
-	l, ok := g.logger.Load(level)
-	if ok {
+	g.updateLogger(level, func(l *logger) {
 		l.mode = mode
 		l.prevMode = mode
 		l.updateMode()
-		g.logger.Store(level, l)
-	}
+	})
 	return g
 }
 
@@ -389,12 +553,7 @@ func SetPrefix(lev LEVEL, pref string) *Glg {
 
 // SetPrefix sets Print logger prefix
 func (g *Glg) SetPrefix(lev LEVEL, pref string) *Glg {
-	l, ok := g.logger.Load(lev)
-	if ok {
-		l.tag = pref
-		l.rawtag = []byte(lsep + l.tag + sep)
-		g.logger.Store(lev, l)
-	}
+	g.setTag(lev, pref)
 	return g
 }
 
@@ -409,11 +568,9 @@ func (g *Glg) GetCurrentMode(level LEVEL) MODE {
 
 // InitWriter is initialize glg writer
 func (g *Glg) InitWriter() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		l.writer = nil
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 	return g
 }
@@ -424,11 +581,9 @@ func (g *Glg) SetWriter(writer io.Writer) *Glg {
 		return g
 	}
 
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		l.writer = writer
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -440,15 +595,13 @@ func (g *Glg) AddWriter(writer io.Writer) *Glg {
 		return g
 	}
 
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		if l.writer == nil {
 			l.writer = writer
 		} else {
-			l.writer = io.MultiWriter(l.writer, writer)
+			l.writer = appendWriter(l.writer, writer)
 		}
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -456,11 +609,9 @@ func (g *Glg) AddWriter(writer io.Writer) *Glg {
 
 // SetLevelColor sets the color for each level
 func (g *Glg) SetLevelColor(level LEVEL, color func(string) string) *Glg {
-	l, ok := g.logger.Load(level)
-	if ok {
+	g.updateLogger(level, func(l *logger) {
 		l.color = color
-		g.logger.Store(level, l)
-	}
+	})
 
 	return g
 }
@@ -471,12 +622,10 @@ func (g *Glg) SetLevelWriter(level LEVEL, writer io.Writer) *Glg {
 		return g
 	}
 
-	l, ok := g.logger.Load(level)
-	if ok {
+	g.updateLogger(level, func(l *logger) {
 		l.writer = writer
 		l.updateMode()
-		g.logger.Store(level, l)
-	}
+	})
 
 	return g
 }
@@ -487,68 +636,55 @@ func (g *Glg) AddLevelWriter(level LEVEL, writer io.Writer) *Glg {
 		return g
 	}
 
-	l, ok := g.logger.Load(level)
-	if ok {
+	g.updateLogger(level, func(l *logger) {
 		if l.writer != nil {
-			l.writer = io.MultiWriter(l.writer, writer)
+			l.writer = appendWriter(l.writer, writer)
 		} else {
 			l.writer = writer
 		}
 		l.updateMode()
-		g.logger.Store(level, l)
-	}
+	})
 
 	return g
 }
 
 // AddStdLevel adds std log level and returns LEVEL
 func (g *Glg) AddStdLevel(tag string, mode MODE, isColor bool) *Glg {
-	return g.addLevel(tag, mode, isColor, os.Stdout)
+	_, err := g.addLevel(tag, mode, isColor, os.Stdout, nil)
+	g.handleError(err)
+	return g
 }
 
 // AddErrLevel adds error log level and returns LEVEL
 func (g *Glg) AddErrLevel(tag string, mode MODE, isColor bool) *Glg {
-	return g.addLevel(tag, mode, isColor, os.Stderr)
-}
-
-func (g *Glg) addLevel(tag string, mode MODE, isColor bool, std io.Writer) *Glg {
-	lev := LEVEL(atomic.AddUint32(g.levelCounter, 1))
-	tag = strings.ToUpper(tag)
-	g.levelMap.Store(tag, lev)
-	l := &logger{
-		writer:   nil,
-		std:      std,
-		color:    Colorless,
-		isColor:  isColor,
-		mode:     mode,
-		prevMode: mode,
-		tag:      tag,
-		rawtag:   []byte(lsep + tag + sep),
-	}
-	l.updateMode()
-	g.logger.Store(lev, l)
+	_, err := g.addLevel(tag, mode, isColor, os.Stderr, nil)
+	g.handleError(err)
 	return g
 }
 
-// SetTimeLocation configures specific time location
+// SetTimeLocation configures time location of all levels
 func (g *Glg) SetTimeLocation(loc *time.Location) *Glg {
-	if loc != nil {
-		fastime.SetLocation(loc)
+	if loc == nil {
+		return g
 	}
+	ts := *g.timeSetting.Load()
+	ts.loc = loc
+	g.timeSetting.Store(&ts)
+	g.updateLoggers(func(l *logger) {
+		g.updateTimestamp(l)
+	})
 	return g
 }
 
-// GetTimeLocation configures specific time location
+// GetTimeLocation returns time location of all levels
 func (g *Glg) GetTimeLocation() (loc *time.Location) {
-	return fastime.GetLocation()
+	return g.timeSetting.Load().loc
 }
 
 // EnableTimestamp enables timestamp output
 func (g *Glg) EnableTimestamp() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		l.disableTimestamp = false
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -556,10 +692,8 @@ func (g *Glg) EnableTimestamp() *Glg {
 
 // DisableTimestamp disables timestamp output
 func (g *Glg) DisableTimestamp() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		l.disableTimestamp = true
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -567,59 +701,54 @@ func (g *Glg) DisableTimestamp() *Glg {
 
 // EnableLevelTimestamp enables timestamp output
 func (g *Glg) EnableLevelTimestamp(lv LEVEL) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.disableTimestamp = false
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
 // DisableLevelTimestamp disables timestamp output
 func (g *Glg) DisableLevelTimestamp(lv LEVEL) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.disableTimestamp = true
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
 // SetCallerDepth configures output line trace caller depth
 func (g *Glg) SetCallerDepth(depth int) *Glg {
 	if depth > DefaultCallerDepth {
-		g.callerDepth = depth
+		g.depth.Store(int64(depth))
 	}
 	return g
 }
 
+// callerDepth returns the line trace caller depth set by SetCallerDepth
+func (g *Glg) callerDepth() int {
+	return int(g.depth.Load())
+}
+
 // SetLineTraceMode configures output line traceFlag
 func (g *Glg) SetLineTraceMode(mode traceMode) *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		l.traceMode = mode
-		g.logger.Store(lev, l)
-		return true
 	})
 	return g
 }
 
 // SetLevelLineTraceMode configures output line traceFlag
 func (g *Glg) SetLevelLineTraceMode(lv LEVEL, mode traceMode) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.traceMode = mode
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
 // EnableColor enables color output
 func (g *Glg) EnableColor() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		l.isColor = true
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -627,11 +756,9 @@ func (g *Glg) EnableColor() *Glg {
 
 // DisableColor disables color output
 func (g *Glg) DisableColor() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(l *logger) {
 		l.isColor = false
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -639,23 +766,19 @@ func (g *Glg) DisableColor() *Glg {
 
 // EnableLevelColor enables color output
 func (g *Glg) EnableLevelColor(lv LEVEL) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.isColor = true
 		l.updateMode()
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
 // DisableLevelColor disables color output
 func (g *Glg) DisableLevelColor(lv LEVEL) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.isColor = false
 		l.updateMode()
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
@@ -682,33 +805,10 @@ func Atol(tag string) LEVEL {
 
 // TagStringToLevel converts level string to Glg.LEVEL
 func (g *Glg) TagStringToLevel(tag string) LEVEL {
-	tag = strings.TrimSpace(strings.ToUpper(tag))
-	lv, ok := g.levelMap.Load(tag)
+	lv, ok := g.levelMap.Load(strings.TrimSpace(strings.ToUpper(tag)))
 	if ok {
 		return lv
 	}
-	switch tag {
-	case DEBG.String(), "DBG", "DEBUG", "D":
-		return DEBG
-	case TRACE.String(), "TRC", "TRA", "TR", "T":
-		return TRACE
-	case PRINT.String(), "PRINT", "PNT", "P":
-		return PRINT
-	case LOG.String(), "LO", "LG", "L":
-		return LOG
-	case INFO.String(), "IFO", "INF", "I":
-		return INFO
-	case OK.String(), "O", "K":
-		return OK
-	case WARN.String(), "WARNING", "WRN", "W":
-		return WARN
-	case ERR.String(), "ERROR", "ER", "E":
-		return ERR
-	case FAIL.String(), "FAILED", "FI":
-		return FAIL
-	case FATAL.String(), "FAT", "FL", "F":
-		return FATAL
-	}
 	return UNKNOWN
 }
 
@@ -897,145 +997,355 @@ func White(str string) string {
 }
 
 func (g *Glg) out(level LEVEL, format string, val ...interface{}) error {
+	return g.output(level, g.callerDepth()+1, 0, g.fields, format, val...)
+}
+
+// Emit logs msg with fields at lv, the line trace is resolved from pc and unknown when pc is zero.
+// It is used by adapters of other logging libraries which resolve the caller by themselves.
+func (g *Glg) Emit(lv LEVEL, pc uintptr, fields []Field, msg string) error {
+	if len(g.fields) != 0 {
+		fields = append(g.fields[:len(g.fields):len(g.fields)], fields...)
+	}
+	return g.output(lv, -1, pc, fields, "%s", msg)
+}
+
+// output writes the log entry with fields.
+// The line trace is resolved from pc if it is not zero, otherwise from the call stack depth.
+func (g *Glg) output(level LEVEL, depth int, pc uintptr, fields []Field, format string, val ...interface{}) error {
+	if depth >= 0 {
+		depth++
+	}
+	return g.outputAt(level, time.Time{}, depth, pc, fields, format, val...)
+}
+
+// outputAt writes the log entry with fields logged at the time at, zero at is the current time
+func (g *Glg) outputAt(level LEVEL, at time.Time, depth int, pc uintptr, fields []Field, format string, val ...interface{}) error {
 	log, ok := g.logger.Load(level)
 	if !ok {
 		return fmt.Errorf("error:\tLog Level %d Not Found", level)
 	}
 
 	if log.mode == NONE {
+		log.stats.suppress()
 		return nil
 	}
 
-	var fl string
-	if log.traceMode&(TraceLineLong|TraceLineShort) != 0 {
-		_, file, line, ok := runtime.Caller(g.callerDepth)
-		switch {
-		case !ok:
-			fl = "???:0"
-		case log.traceMode&TraceLineShort != 0:
-			for i := len(file) - 1; i > 0; i-- {
-				if file[i] == '/' {
-					file = file[i+1:]
-					break
-				}
-			}
-			fl = file + ":" + strconv.Itoa(line)
-		case strings.HasPrefix(file, runtime.GOROOT()+"/src"):
-			fl = "https://github.com/golang/go/blob/" + runtime.Version() + strings.TrimPrefix(file, runtime.GOROOT()) + "#L" + strconv.Itoa(line)
-		case strings.Contains(file, "go/pkg/mod/"):
-			fl = "https:/"
-			for _, path := range strings.Split(strings.SplitN(file, "go/pkg/mod/", 2)[1], "/") {
-				left, right, ok := strings.Cut(path, "@")
-				if ok {
-					if strings.Count(right, "-") > 2 {
-						path = left + "/blob/main"
-					} else {
-						path = left + "/blob/" + right
-					}
-				}
-				fl += "/" + path
-			}
-			fl += "#L" + strconv.Itoa(line)
-		case strings.Contains(file, "go/src"):
-			fl = "https:/"
-			cnt := 0
-			for _, path := range strings.Split(strings.SplitN(file, "go/src/", 2)[1], "/") {
-				if cnt == 3 {
-					path = "blob/main/" + path
-				}
-				fl += "/" + path
-				cnt++
+	if log.sampler != nil {
+		ok, suppressed := log.sampler.allow(g.now())
+		if suppressed != 0 {
+			err := g.write(level, log, time.Time{}, -1, 0, nil, "suppressed %d messages", suppressed)
+			if err != nil {
+				return err
 			}
-			fl += "#L" + strconv.Itoa(line)
-		default:
-			fl = file + ":" + strconv.Itoa(line)
+		}
+		if !ok {
+			return nil
 		}
 	}
 
-	if g.enableJSON {
-		var w io.Writer
-		switch log.writeMode {
-		case writeStd, writeColorStd:
-			w = log.std
-		case writeWriter:
-			w = log.writer
-		case writeBoth, writeColorBoth:
-			w = io.MultiWriter(log.std, log.writer)
-		default:
+	if depth >= 0 {
+		depth++
+	}
+	return g.write(level, log, at, depth, pc, fields, format, val...)
+}
+
+// write builds the log entry logged at the time at, fires the hooks and writes it to the destinations of log.
+// Zero at is the current time.
+func (g *Glg) write(level LEVEL, log *logger, at time.Time, depth int, pc uintptr, fields []Field, format string, val ...interface{}) error {
+	now := at
+	if now.IsZero() {
+		now = g.entryTime(log)
+	}
+	e := newEntry(level, log, now, fields, format, val)
+	defer releaseEntry(e)
+	if log.traceMode&(TraceLineLong|TraceLineShort) != 0 {
+		file, line, ok := caller(depth, pc)
+		e.Caller = traceString(log.traceMode, file, line, ok)
+	}
+
+	if hs := g.hooks.Load(); hs != nil {
+		if !g.fireHooks(*hs, log, e, now) {
 			return nil
 		}
-		var detail interface{}
-		if format != "" {
-			detail = fmt.Sprintf(format, val...)
-		} else if len(val) > 1 {
-			detail = val
-		} else {
-			detail = val[0]
+	}
+
+	if log.dedup != nil {
+		repeated, ok := log.dedup.check(e, callerPC(depth, pc), now)
+		if repeated != 0 {
+			if err := g.emitRepeats(log, level, now, repeated); err != nil {
+				return err
+			}
 		}
-		var timestamp string
-		if !log.disableTimestamp {
-			fn := fastime.FormattedNow()
-			timestamp = *(*string)(unsafe.Pointer(&fn))
+		if !ok {
+			return nil
 		}
-		return json.NewEncoder(w).Encode(JSONFormat{
-			Date:   timestamp,
-			Level:  log.tag,
-			File:   fl,
-			Detail: detail,
-		})
 	}
 
-	var (
-		buf []byte
-		err error
-		b   = g.buffer.Get().(*bytes.Buffer)
-	)
+	return g.emit(log, e)
+}
 
-	if log.disableTimestamp {
-		b.Write(log.rawtag[len(tab):])
-	} else {
-		b.Write(fastime.FormattedNow())
-		b.Write(log.rawtag)
+// newEntry returns pooled entry of log logged at now, it is put back by releaseEntry
+func newEntry(level LEVEL, log *logger, now time.Time, fields []Field, format string, val []interface{}) *Entry {
+	e := entryPool.Get().(*Entry)
+	*e = Entry{
+		Level:  level,
+		Tag:    log.tag,
+		Fields: fields,
+		format: format,
+		args:   val,
+	}
+	if !log.disableTimestamp {
+		e.Time = now.In(log.timestamp.loc)
+		e.Timestamp = log.timestamp.format(now)
 	}
-	if len(fl) != 0 {
-		b.WriteString("(" + fl + "):\t")
+	if format == "" {
+		e.Values = val
+		if e.Values == nil {
+			e.Values = []interface{}{}
+		}
 	}
-	b.WriteString(format)
+	return e
+}
 
-	switch {
-	case log.writeMode^writeColorStd == 0:
-		buf = b.Bytes()
-		_, err = fmt.Fprintf(log.std, log.color(*(*string)(unsafe.Pointer(&buf)))+rc, val...)
-	case log.writeMode^writeStd == 0:
-		b.WriteString(rc)
-		buf = b.Bytes()
-		_, err = fmt.Fprintf(log.std, *(*string)(unsafe.Pointer(&buf)), val...)
-	case log.writeMode^writeWriter == 0:
-		b.WriteString(rc)
-		buf = b.Bytes()
-		_, err = fmt.Fprintf(log.writer, *(*string)(unsafe.Pointer(&buf)), val...)
-	case log.writeMode^writeColorBoth == 0:
-		buf = b.Bytes()
-		str := *(*string)(unsafe.Pointer(&buf))
-		_, err = fmt.Fprintf(log.std, log.color(str)+rc, val...)
-		if err == nil {
-			_, err = fmt.Fprintf(log.writer, str+rc, val...)
+func releaseEntry(e *Entry) {
+	*e = Entry{}
+	entryPool.Put(e)
+}
+
+// emit encodes the log entry and writes it to the destinations of log
+func (g *Glg) emit(log *logger, e *Entry) error {
+	if len(log.dsts) == 0 {
+		return nil
+	}
+
+	b := g.buffer.Get().(*bytes.Buffer)
+	defer func() {
+		bl := uint64(b.Len())
+		if atomic.LoadUint64(g.bs) < bl {
+			atomic.StoreUint64(g.bs, bl)
+		}
+		b.Reset()
+		g.buffer.Put(b)
+	}()
+
+	var line []byte
+	for _, d := range log.dsts {
+		if !d.reuse {
+			if e.repeat != 0 {
+				e.Fields = repeatFields(d.enc, e.repeat)
+			}
+			if d.custom {
+				e.message()
+			}
+			b.Reset()
+			err := d.enc.EncodeEntry(b, e)
+			if err != nil {
+				return err
+			}
+			line = b.Bytes()
+		}
+		out := line
+		if d.color && len(line) >= rcl {
+			out = []byte(log.color(string(line[:len(line)-rcl])) + rc)
+		}
+		err := g.writeTo(e.Level, log.stats, d.w, out)
+		if err != nil {
+			log.stats.fail()
+			return err
 		}
-	case log.writeMode^writeBoth == 0:
-		b.WriteString(rc)
-		buf = b.Bytes()
-		_, err = fmt.Fprintf(io.MultiWriter(log.std, log.writer), *(*string)(unsafe.Pointer(&buf)), val...)
 	}
-	bl := uint64(len(buf))
-	if atomic.LoadUint64(g.bs) < bl {
-		atomic.StoreUint64(g.bs, bl)
+	log.stats.emit()
+	return nil
+}
+
+// multiWriter writes to all of the writers like io.MultiWriter,
+// emit encodes the entry for each of them so that the writers can have own encoders.
+type multiWriter []io.Writer
+
+func (mw multiWriter) Write(b []byte) (n int, err error) {
+	for _, w := range mw {
+		n, err = w.Write(b)
+		if err != nil {
+			return n, err
+		}
+		if n != len(b) {
+			return n, io.ErrShortWrite
+		}
 	}
-	b.Reset()
-	g.buffer.Put(b)
+	return len(b), nil
+}
 
+// appendWriter returns writer which writes to dst and w
+func appendWriter(dst, w io.Writer) io.Writer {
+	if dst == nil {
+		return w
+	}
+	if mw, ok := dst.(multiWriter); ok {
+		return append(mw[:len(mw):len(mw)], w)
+	}
+	return multiWriter{dst, w}
+}
+
+// writeTo writes the encoded entry to w, it is queued when async logging is enabled
+func (g *Glg) writeTo(level LEVEL, st *levelStats, w io.Writer, b []byte) error {
+	if p := g.async.Load(); p != nil {
+		p.push(level, st, w, append([]byte(nil), b...))
+		return nil
+	}
+	_, err := w.Write(b)
 	return err
 }
 
+// traceString formats the line trace of file and line by mode
+func traceString(mode traceMode, file string, line int, ok bool) (fl string) {
+	switch {
+	case !ok:
+		fl = "???:0"
+	case mode&TraceLineShort != 0:
+		for i := len(file) - 1; i > 0; i-- {
+			if file[i] == '/' {
+				file = file[i+1:]
+				break
+			}
+		}
+		fl = file + ":" + strconv.Itoa(line)
+	case strings.HasPrefix(file, runtime.GOROOT()+"/src"):
+		fl = "https://github.com/golang/go/blob/" + runtime.Version() + strings.TrimPrefix(file, runtime.GOROOT()) + "#L" + strconv.Itoa(line)
+	case strings.Contains(file, "go/pkg/mod/"):
+		fl = "https:/"
+		for _, path := range strings.Split(strings.SplitN(file, "go/pkg/mod/", 2)[1], "/") {
+			left, right, ok := strings.Cut(path, "@")
+			if ok {
+				if strings.Count(right, "-") > 2 {
+					path = left + "/blob/main"
+				} else {
+					path = left + "/blob/" + right
+				}
+			}
+			fl += "/" + path
+		}
+		fl += "#L" + strconv.Itoa(line)
+	case strings.Contains(file, "go/src"):
+		fl = "https:/"
+		cnt := 0
+		for _, path := range strings.Split(strings.SplitN(file, "go/src/", 2)[1], "/") {
+			if cnt == 3 {
+				path = "blob/main/" + path
+			}
+			fl += "/" + path
+			cnt++
+		}
+		fl += "#L" + strconv.Itoa(line)
+	default:
+		fl = file + ":" + strconv.Itoa(line)
+	}
+	return fl
+}
+
+// caller returns file and line of pc, or of the caller at depth when pc is zero.
+// Negative depth means the caller is unknown.
+func caller(depth int, pc uintptr) (file string, line int, ok bool) {
+	if pc != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		return frame.File, frame.Line, frame.File != ""
+	}
+	if depth < 0 {
+		return "", 0, false
+	}
+	_, file, line, ok = runtime.Caller(depth + 1)
+	return file, line, ok
+}
+
+// callerPC returns pc, or the program counter of the caller at depth when pc is zero
+func callerPC(depth int, pc uintptr) uintptr {
+	if pc != 0 || depth < 0 {
+		return pc
+	}
+	pc, _, _, _ = runtime.Caller(depth + 1)
+	return pc
+}
+
+// appendTextFields writes fields as tab separated key=value pairs
+func appendTextFields(b *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		b.WriteString(tab)
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(fieldString(f.Value))
+	}
+}
+
+// fieldString returns text representation of field value, quoted when it contains separators
+func fieldString(val interface{}) string {
+	str := fieldValueString(val)
+	if str == "" || strings.ContainsAny(str, " \t\r\n\"=") {
+		return strconv.Quote(str)
+	}
+	return str
+}
+
+// fieldValueString returns text representation of field value
+func fieldValueString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(val)
+}
+
+// jsonFieldValue converts field value to the value which can be marshaled meaningfully
+func jsonFieldValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case error:
+		return v.Error()
+	}
+	return val
+}
+
+// jsonFormat is JSONFormat without MarshalJSON, it is encoded without the fields
+type jsonFormat JSONFormat
+
+// MarshalJSON encodes JSONFormat and puts fields as top level keys.
+// Field keys conflicting with the reserved keys are prefixed by "fields.".
+func (j JSONFormat) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(jsonFormat(j))
+	if err != nil || len(j.Fields) == 0 {
+		return b, err
+	}
+	return appendJSONFields(b[:len(b)-1], j.Fields)
+}
+
+// appendJSONFields appends fields as keys of the JSON object b whose closing brace is removed, then closes it
+func appendJSONFields(b []byte, fields []Field) ([]byte, error) {
+	for _, f := range fields {
+		key := f.Key
+		switch key {
+		case "date", "level", "file", "detail":
+			key = "fields." + key
+		}
+		kb, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(jsonFieldValue(f.Value))
+		if err != nil {
+			vb, err = json.Marshal(fmt.Sprint(f.Value))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(b) > 1 {
+			b = append(b, ',')
+		}
+		b = append(append(append(b, kb...), ':'), vb...)
+	}
+	return append(b, '}'), nil
+}
+
 // Log writes std log event
 func (g *Glg) Log(val ...interface{}) error {
 	return g.out(LOG, g.blankFormat(len(val)), val...)
@@ -1417,7 +1727,7 @@ func (g *Glg) Fatal(val ...interface{}) {
 			panic(err)
 		}
 	}
-	exit(1)
+	g.fatalExit(1)
 }
 
 // Fatalln outputs line fixed Failed log and exit program
@@ -1429,7 +1739,7 @@ func (g *Glg) Fatalln(val ...interface{}) {
 			panic(err)
 		}
 	}
-	exit(1)
+	g.fatalExit(1)
 }
 
 // Fatalf outputs formatted Failed log and exit program
@@ -1441,7 +1751,7 @@ func (g *Glg) Fatalf(format string, val ...interface{}) {
 			panic(err)
 		}
 	}
-	exit(1)
+	g.fatalExit(1)
 }
 
 // Fatal outputs Failed log and exit program
@@ -1453,7 +1763,7 @@ func Fatal(val ...interface{}) {
 			panic(err)
 		}
 	}
-	exit(1)
+	glg.fatalExit(1)
 }
 
 // Fatalf outputs formatted Failed log and exit program
@@ -1465,7 +1775,7 @@ func Fatalf(format string, val ...interface{}) {
 			panic(err)
 		}
 	}
-	exit(1)
+	glg.fatalExit(1)
 }
 
 // Fatalln outputs line fixed Failed log and exit program
@@ -1477,7 +1787,7 @@ func Fatalln(val ...interface{}) {
 			panic(err)
 		}
 	}
-	exit(1)
+	glg.fatalExit(1)
 }
 
 // ReplaceExitFunc replaces exit function.
@@ -1500,7 +1810,7 @@ func (g *Glg) Reset() *Glg {
 }
 
 func (g *Glg) blankFormat(l int) string {
-	if g.enableJSON {
+	if g.enableJSON.Load() {
 		return ""
 	}
 	if dfl > l {
@@ -30,9 +30,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -767,6 +769,18 @@ func TestGlg_SetPrefix(t *testing.T) {
 	}
 }
 
+func TestGlg_SetPrefixTagStringToLevel(t *testing.T) {
+	g := New().SetPrefix(INFO, "information").SetPrefix(INFO, "info2")
+	for _, tag := range []string{"INFO", "info", "INFO2", "I"} {
+		if got := g.TagStringToLevel(tag); got != INFO {
+			t.Errorf("TagStringToLevel(%q) after SetPrefix = %v, want %v", tag, got, INFO)
+		}
+	}
+	if got := g.TagStringToLevel("INFORMATION"); got != UNKNOWN {
+		t.Errorf("TagStringToLevel(%q) of replaced prefix = %v, want %v", "INFORMATION", got, UNKNOWN)
+	}
+}
+
 func TestGlg_EnableColor(t *testing.T) {
 	tests := []struct {
 		name string
@@ -948,6 +962,7 @@ func TestGlg_TagStringToLevel(t *testing.T) {
 }
 
 func TestFileWriter(t *testing.T) {
+	dir := t.TempDir()
 	tests := []struct {
 		name  string
 		path  string
@@ -956,14 +971,14 @@ func TestFileWriter(t *testing.T) {
 	}{
 		{
 			name:  "sample file log",
-			path:  "./sample.log",
-			want:  "./sample.log",
+			path:  filepath.Join(dir, "sample.log"),
+			want:  filepath.Join(dir, "sample.log"),
 			isErr: false,
 		},
 		{
 			name:  "error file log",
-			path:  "./error.log",
-			want:  "./error.log",
+			path:  filepath.Join(dir, "error.log"),
+			want:  filepath.Join(dir, "error.log"),
 			isErr: false,
 		},
 		{
@@ -989,6 +1004,7 @@ func TestFileWriter(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			f := FileWriter(tt.path, 0o755)
 			if f != nil {
+				defer f.Close()
 				got := f.Name()
 				if !tt.isErr && !reflect.DeepEqual(got, tt.want) {
 					t.Errorf("FileWriter() = %v, want %v", got, tt.want)
@@ -1569,6 +1585,17 @@ func TestGlg_out(t *testing.T) {
 	}
 }
 
+func TestGlg_outLineTrace(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineShort)
+	if err := g.Info("trace"); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(buf.String(), "(glg_test.go:") {
+		t.Errorf("Glg.out() line trace = %v", buf.String())
+	}
+}
+
 func TestGlg_Log(t *testing.T) {
 	tests := []struct {
 		name string
@@ -4023,6 +4050,9 @@ func TestFatalln(t *testing.T) {
 }
 
 func TestReplaceExitFunc(t *testing.T) {
+	defer func(fn func(int)) {
+		exit = fn
+	}(exit)
 	tests := []struct {
 		name string
 		fn   func(i int)
@@ -4213,7 +4243,7 @@ func (d dumpWriter) Read(p []byte) (n int, err error) {
 }
 
 func TestGlg_EnableJSON(t *testing.T) {
-	if Get().EnableJSON().enableJSON != true {
+	if !Get().EnableJSON().enableJSON.Load() {
 		t.Error("json mode is not enabled")
 	}
 	var d dumpWriter
@@ -4237,11 +4267,113 @@ func TestGlg_EnableJSON(t *testing.T) {
 }
 
 func TestGlg_DisableJSON(t *testing.T) {
-	if Get().DisableJSON().enableJSON != false {
+	if Get().DisableJSON().enableJSON.Load() {
 		t.Error("json mode is not disables")
 	}
 }
 
+func TestGlg_With(t *testing.T) {
+	tests := []struct {
+		name string
+		kv   []interface{}
+		want string
+	}{
+		{
+			name: "key value pairs",
+			kv:   []interface{}{"user_id", 10, "req", "abc"},
+			want: "\tuser_id=10\treq=abc",
+		},
+		{
+			name: "quoted value",
+			kv:   []interface{}{"msg", "hello world"},
+			want: "\tmsg=\"hello world\"",
+		},
+		{
+			name: "field value",
+			kv:   []interface{}{Field{Key: "err", Value: errors.New("failed")}},
+			want: "\terr=failed",
+		},
+		{
+			name: "percent value",
+			kv:   []interface{}{"rate", "100%"},
+			want: "\trate=100%",
+		},
+		{
+			name: "missing value",
+			kv:   []interface{}{"user_id", 10, "orphan"},
+			want: "\tuser_id=10\t" + badKey + "=orphan",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf)
+			err := g.With(tt.kv...).Info("sample")
+			if err != nil {
+				t.Errorf("Glg.With().Info() unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(buf.String(), "sample"+tt.want+"\n") {
+				t.Errorf("Glg.With().Info() = got %v want suffix %v", buf.String(), tt.want)
+			}
+		})
+	}
+
+	t.Run("child shares configuration and keeps parent fields", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		g := New().SetMode(WRITER).SetWriter(buf).With("a", 1)
+		child := g.With("b", 2)
+		g.SetLevel(WARN)
+		if err := child.Info("hidden"); err != nil {
+			t.Error(err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("child did not follow parent level: %v", buf.String())
+		}
+		if err := child.Warn("shown"); err != nil {
+			t.Error(err)
+		}
+		if !strings.HasSuffix(buf.String(), "shown\ta=1\tb=2\n") {
+			t.Errorf("Glg.With() = got %v", buf.String())
+		}
+		if len(g.Fields()) != 1 || len(child.Fields()) != 2 {
+			t.Errorf("Glg.Fields() = %v, %v", g.Fields(), child.Fields())
+		}
+	})
+
+	t.Run("json fields", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		g := New().SetMode(WRITER).SetWriter(buf).EnableJSON()
+		err := g.With("user_id", 10, "level", "dup", "err", errors.New("failed")).Info("sample")
+		if err != nil {
+			t.Error(err)
+		}
+		var got map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]interface{}{
+			"date":         got["date"],
+			"level":        INFO.String(),
+			"detail":       "sample",
+			"user_id":      float64(10),
+			"fields.level": "dup",
+			"err":          "failed",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Glg.With() json = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestWith(t *testing.T) {
+	if got := With("a", 1).Fields(); !reflect.DeepEqual(got, []Field{{Key: "a", Value: 1}}) {
+		t.Errorf("With() = %v", got)
+	}
+	if len(Get().Fields()) != 0 {
+		t.Error("With() modified global instance fields")
+	}
+}
+
 func TestGlg_EnablePoolBuffer(t *testing.T) {
 	g := Get().EnablePoolBuffer(100)
 	_, ok := g.buffer.Get().(*bytes.Buffer)
@@ -4335,11 +4467,11 @@ func Test_logger_updateMode(t *testing.T) {
 func TestGlg_EnableTimestamp(t *testing.T) {
 	type fields struct {
 		bs           *uint64
-		logger       loggers
+		logger       *loggers
 		levelCounter *uint32
-		levelMap     levelMap
-		buffer       sync.Pool
-		enableJSON   bool
+		levelMap     *levelMap
+		buffer       *sync.Pool
+		enableJSON   *atomic.Bool
 	}
 	tests := []struct {
 		name   string
@@ -4368,11 +4500,11 @@ func TestGlg_EnableTimestamp(t *testing.T) {
 func TestGlg_DisableTimestamp(t *testing.T) {
 	type fields struct {
 		bs           *uint64
-		logger       loggers
+		logger       *loggers
 		levelCounter *uint32
-		levelMap     levelMap
-		buffer       sync.Pool
-		enableJSON   bool
+		levelMap     *levelMap
+		buffer       *sync.Pool
+		enableJSON   *atomic.Bool
 	}
 	tests := []struct {
 		name   string
@@ -4401,11 +4533,11 @@ func TestGlg_DisableTimestamp(t *testing.T) {
 func TestGlg_EnableLevelTimestamp(t *testing.T) {
 	type fields struct {
 		bs           *uint64
-		logger       loggers
+		logger       *loggers
 		levelCounter *uint32
-		levelMap     levelMap
-		buffer       sync.Pool
-		enableJSON   bool
+		levelMap     *levelMap
+		buffer       *sync.Pool
+		enableJSON   *atomic.Bool
 	}
 	type args struct {
 		lv LEVEL
@@ -4438,11 +4570,11 @@ func TestGlg_EnableLevelTimestamp(t *testing.T) {
 func TestGlg_DisableLevelTimestamp(t *testing.T) {
 	type fields struct {
 		bs           *uint64
-		logger       loggers
+		logger       *loggers
 		levelCounter *uint32
-		levelMap     levelMap
-		buffer       sync.Pool
-		enableJSON   bool
+		levelMap     *levelMap
+		buffer       *sync.Pool
+		enableJSON   *atomic.Bool
 	}
 	type args struct {
 		lv LEVEL
@@ -4475,11 +4607,11 @@ func TestGlg_DisableLevelTimestamp(t *testing.T) {
 func TestGlg_blankFormat(t *testing.T) {
 	type fields struct {
 		bs           *uint64
-		logger       loggers
+		logger       *loggers
 		levelCounter *uint32
-		levelMap     levelMap
-		buffer       sync.Pool
-		enableJSON   bool
+		levelMap     *levelMap
+		buffer       *sync.Pool
+		enableJSON   *atomic.Bool
 	}
 	type args struct {
 		l int
@@ -4532,11 +4664,11 @@ func Test_isModeEnable(t *testing.T) {
 func TestGlg_isModeEnable(t *testing.T) {
 	type fields struct {
 		bs           *uint64
-		logger       loggers
+		logger       *loggers
 		levelCounter *uint32
-		levelMap     levelMap
-		buffer       sync.Pool
-		enableJSON   bool
+		levelMap     *levelMap
+		buffer       *sync.Pool
+		enableJSON   *atomic.Bool
 	}
 	type args struct {
 		l LEVEL
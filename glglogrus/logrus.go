@@ -0,0 +1,164 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glglogrus provides logrus Hook and Formatter which log and format logrus entries by glg
+package glglogrus
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/kpango/glg"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultLevels returns the level mapping described in NewHook
+func defaultLevels() map[logrus.Level]glg.LEVEL {
+	return map[logrus.Level]glg.LEVEL{
+		logrus.TraceLevel: glg.TRACE,
+		logrus.DebugLevel: glg.DEBG,
+		logrus.InfoLevel:  glg.INFO,
+		logrus.WarnLevel:  glg.WARN,
+		logrus.ErrorLevel: glg.ERR,
+		logrus.FatalLevel: glg.FATAL,
+		logrus.PanicLevel: glg.PANIC,
+	}
+}
+
+// mapLevel returns copy of levels in which ll is mapped to lv
+func mapLevel(levels map[logrus.Level]glg.LEVEL, ll logrus.Level, lv glg.LEVEL) map[logrus.Level]glg.LEVEL {
+	nl := make(map[logrus.Level]glg.LEVEL, len(levels)+1)
+	for k, v := range levels {
+		nl[k] = v
+	}
+	nl[ll] = lv
+	return nl
+}
+
+// level returns glg level for logrus level, INFO is used for the levels which are not mapped
+func level(levels map[logrus.Level]glg.LEVEL, ll logrus.Level) glg.LEVEL {
+	if lv, ok := levels[ll]; ok {
+		return lv
+	}
+	return glg.INFO
+}
+
+// Hook is logrus.Hook which logs logrus entries through glg instance.
+// Set the output of the logrus logger to io.Discard when glg is the only destination.
+type Hook struct {
+	g      *glg.Glg
+	levels map[logrus.Level]glg.LEVEL
+}
+
+// NewHook returns Hook which logs to g.
+// logrus TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel and PanicLevel
+// are mapped to TRACE, DEBG, INFO, WARN, ERR, FATAL and PANIC.
+func NewHook(g *glg.Glg) *Hook {
+	return &Hook{
+		g:      g,
+		levels: defaultLevels(),
+	}
+}
+
+// MapLevel returns hook which logs the entries of logrus level ll as glg level lv
+func (h *Hook) MapLevel(ll logrus.Level, lv glg.LEVEL) *Hook {
+	nh := *h
+	nh.levels = mapLevel(h.levels, ll, lv)
+	return &nh
+}
+
+// Levels returns all logrus levels, the entries are filtered by the glg levels
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire logs e with its data as fields sorted by key
+func (h *Hook) Fire(e *logrus.Entry) error {
+	return h.g.Emit(level(h.levels, e.Level), callerPC(e), fields(e), e.Message)
+}
+
+// Formatter is logrus.Formatter which formats logrus entries like the entries of glg instance,
+// by the encoder, time format and line trace mode of the mapped glg level.
+type Formatter struct {
+	g      *glg.Glg
+	levels map[logrus.Level]glg.LEVEL
+}
+
+// NewFormatter returns Formatter which formats like g with the level mapping of NewHook
+func NewFormatter(g *glg.Glg) *Formatter {
+	return &Formatter{
+		g:      g,
+		levels: defaultLevels(),
+	}
+}
+
+// MapLevel returns formatter which formats the entries of logrus level ll as glg level lv
+func (f *Formatter) MapLevel(ll logrus.Level, lv glg.LEVEL) *Formatter {
+	nf := *f
+	nf.levels = mapLevel(f.levels, ll, lv)
+	return &nf
+}
+
+// Format returns e encoded by the glg level
+func (f *Formatter) Format(e *logrus.Entry) ([]byte, error) {
+	b := e.Buffer
+	if b == nil {
+		b = new(bytes.Buffer)
+	}
+	err := f.g.EncodeEntry(b, &glg.Entry{
+		Time:    e.Time,
+		Level:   level(f.levels, e.Level),
+		Message: e.Message,
+		Fields:  fields(e),
+		PC:      callerPC(e),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// fields returns the data of e as fields sorted by key
+func fields(e *logrus.Entry) []glg.Field {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(e.Data))
+	for k := range e.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fs := make([]glg.Field, 0, len(keys))
+	for _, k := range keys {
+		fs = append(fs, glg.Field{Key: k, Value: e.Data[k]})
+	}
+	return fs
+}
+
+// callerPC returns the return address of the caller recorded by logrus ReportCaller, or zero
+func callerPC(e *logrus.Entry) uintptr {
+	if e.Caller == nil || e.Caller.PC == 0 {
+		return 0
+	}
+	// logrus records the pc of the call instruction, glg expects the return address
+	return e.Caller.PC + 1
+}
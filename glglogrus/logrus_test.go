@@ -0,0 +1,135 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glglogrus provides logrus Hook and Formatter which log and format logrus entries by glg
+package glglogrus
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/kpango/glg"
+	"github.com/kpango/glg/glgtest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHook(t *testing.T) {
+	g, o := glgtest.New()
+	g.SetLevel(glg.DEBG)
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.SetLevel(logrus.TraceLevel)
+	l.SetReportCaller(true)
+	l.AddHook(NewHook(g).MapLevel(logrus.TraceLevel, glg.DEBG))
+
+	l.Trace("trace")
+	l.WithFields(logrus.Fields{"user": "alice", "attempt": 2}).Warn("login failed")
+	l.WithField("path", "/tmp").Error("not found")
+	func() {
+		defer func() {
+			recover()
+		}()
+		l.Panic("unreachable")
+	}()
+
+	want := []glgtest.Entry{
+		{Level: glg.DEBG, Tag: "DEBG", Message: "trace"},
+		{
+			Level:   glg.WARN,
+			Tag:     "WARN",
+			Message: "login failed",
+			Fields:  []glg.Field{{Key: "attempt", Value: 2}, {Key: "user", Value: "alice"}},
+		},
+		{Level: glg.ERR, Tag: "ERR", Message: "not found", Fields: []glg.Field{{Key: "path", Value: "/tmp"}}},
+		{Level: glg.PANIC, Tag: "PANIC", Message: "unreachable"},
+	}
+	got := o.All()
+	if len(got) != len(want) {
+		t.Fatalf("logged %d entries, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Level != want[i].Level || got[i].Tag != want[i].Tag || got[i].Message != want[i].Message ||
+			!reflect.DeepEqual(got[i].Fields, want[i].Fields) {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+		if !regexp.MustCompile(`^logrus_test\.go:\d+$`).MatchString(got[i].Caller) {
+			t.Errorf("entry %d caller = %q", i, got[i].Caller)
+		}
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		setup func(g *glg.Glg) *glg.Glg
+		entry *logrus.Entry
+		want  string
+	}{
+		{
+			name:  "text",
+			setup: func(g *glg.Glg) *glg.Glg { return g },
+			entry: &logrus.Entry{Time: now, Level: logrus.WarnLevel, Message: "msg", Data: logrus.Fields{"b": 2, "a": "x y"}},
+			want:  "2024-01-02 03:04:05\t[WARN]:\tmsg\ta=\"x y\"\tb=2\n",
+		},
+		{
+			name:  "json without timestamp",
+			setup: func(g *glg.Glg) *glg.Glg { return g.EnableJSON().DisableTimestamp() },
+			entry: &logrus.Entry{Time: now, Level: logrus.ErrorLevel, Message: "msg", Data: logrus.Fields{"k": "v"}},
+			want:  `{"level":"ERR","detail":"msg","k":"v"}` + "\n",
+		},
+		{
+			name:  "level time format",
+			setup: func(g *glg.Glg) *glg.Glg { return g.SetLevelTimeFormat(glg.INFO, glg.TimeFormatUnix) },
+			entry: &logrus.Entry{Time: now, Level: logrus.InfoLevel, Message: "msg"},
+			want:  "1704164645\t[INFO]:\tmsg\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.setup(glg.New().SetTimeLocation(time.UTC).SetLineTraceMode(glg.TraceLineNone))
+			b, err := NewFormatter(g).Format(tt.entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("logger output", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		l := logrus.New()
+		l.SetOutput(buf)
+		l.SetFormatter(NewFormatter(glg.New().DisableTimestamp().SetLineTraceMode(glg.TraceLineNone)))
+		l.Info("first")
+		l.Info("second")
+		if got, want := buf.String(), "[INFO]:\tfirst\n[INFO]:\tsecond\n"; got != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	})
+}
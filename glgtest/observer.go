@@ -0,0 +1,224 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glgtest provides helpers for testing code which logs with glg
+package glgtest
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kpango/glg"
+)
+
+// Entry is log entry recorded by Observer
+type Entry struct {
+	Time    time.Time
+	Level   glg.LEVEL
+	Tag     string
+	Message string
+	Fields  []glg.Field
+	Caller  string
+}
+
+// Entries is list of recorded entries in logged order
+type Entries []Entry
+
+// Observer records the entries logged to it, it is io.Writer encoding entries by itself
+// so that it can be added to glg instance by SetWriter, AddWriter or SetLevelWriter.
+type Observer struct {
+	mu      sync.RWMutex
+	entries Entries
+}
+
+// NewObserver returns empty Observer
+func NewObserver() *Observer {
+	return new(Observer)
+}
+
+// New returns glg instance which records entries of all levels to the returned Observer.
+// Color and std output are disabled and the caller is recorded by TraceLineShort.
+// Custom levels added later need SetLevelWriter(lv, observer) to be recorded.
+func New() (*glg.Glg, *Observer) {
+	o := NewObserver()
+	g := glg.New().
+		SetMode(glg.WRITER).
+		DisableColor().
+		SetLineTraceMode(glg.TraceLineShort).
+		SetWriter(o)
+	return g, o
+}
+
+// EncodeEntry records e, nothing is written to buf
+func (o *Observer) EncodeEntry(_ *bytes.Buffer, e *glg.Entry) error {
+	var fields []glg.Field
+	if len(e.Fields) != 0 {
+		fields = append(fields, e.Fields...)
+	}
+	o.mu.Lock()
+	o.entries = append(o.entries, Entry{
+		Time:    e.Time,
+		Level:   e.Level,
+		Tag:     e.Tag,
+		Message: e.Message,
+		Fields:  fields,
+		Caller:  e.Caller,
+	})
+	o.mu.Unlock()
+	return nil
+}
+
+// Write discards b, the entries are recorded by EncodeEntry
+func (o *Observer) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Len returns the number of recorded entries
+func (o *Observer) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.entries)
+}
+
+// All returns copy of all recorded entries
+func (o *Observer) All() Entries {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return append(Entries(nil), o.entries...)
+}
+
+// TakeAll returns all recorded entries and resets the observer
+func (o *Observer) TakeAll() Entries {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// FilterLevel returns the recorded entries of lv
+func (o *Observer) FilterLevel(lv glg.LEVEL) Entries {
+	return o.All().FilterLevel(lv)
+}
+
+// FilterMessageContains returns the recorded entries whose message contains substr
+func (o *Observer) FilterMessageContains(substr string) Entries {
+	return o.All().FilterMessageContains(substr)
+}
+
+// AssertLogged reports an error to tb unless an entry of lv whose message contains substr is recorded
+func (o *Observer) AssertLogged(tb testing.TB, lv glg.LEVEL, substr string) bool {
+	tb.Helper()
+	all := o.All()
+	if len(all.FilterLevel(lv).FilterMessageContains(substr)) != 0 {
+		return true
+	}
+	var sb strings.Builder
+	for _, e := range all {
+		sb.WriteString("\n\t")
+		sb.WriteString(e.String())
+	}
+	tb.Errorf("no %s entry containing %q is logged, logged entries:%s", tagOf(lv), substr, sb.String())
+	return false
+}
+
+// FilterLevel returns the entries of lv
+func (es Entries) FilterLevel(lv glg.LEVEL) Entries {
+	return es.Filter(func(e Entry) bool {
+		return e.Level == lv
+	})
+}
+
+// FilterTag returns the entries whose tag is tag, the tags of custom levels are upper case
+func (es Entries) FilterTag(tag string) Entries {
+	return es.Filter(func(e Entry) bool {
+		return e.Tag == tag
+	})
+}
+
+// FilterMessageContains returns the entries whose message contains substr
+func (es Entries) FilterMessageContains(substr string) Entries {
+	return es.Filter(func(e Entry) bool {
+		return strings.Contains(e.Message, substr)
+	})
+}
+
+// FilterField returns the entries having field of key whose value is val
+func (es Entries) FilterField(key string, val interface{}) Entries {
+	return es.Filter(func(e Entry) bool {
+		v, ok := e.Field(key)
+		return ok && v == val
+	})
+}
+
+// Filter returns the entries for which fn returns true
+func (es Entries) Filter(fn func(Entry) bool) Entries {
+	var filtered Entries
+	for _, e := range es {
+		if fn(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// Messages returns the messages of the entries
+func (es Entries) Messages() []string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+// Field returns the value of the last field of key
+func (e Entry) Field(key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// String returns e formatted such as "[INFO]: message key=value"
+func (e Entry) String() string {
+	var sb strings.Builder
+	sb.WriteString("[" + e.Tag + "]: " + e.Message)
+	for _, f := range e.Fields {
+		sb.WriteString(" " + f.Key + "=")
+		sb.WriteString(fmt.Sprint(f.Value))
+	}
+	return sb.String()
+}
+
+func tagOf(lv glg.LEVEL) string {
+	if tag := lv.String(); tag != "" {
+		return tag
+	}
+	return "level " + strconv.Itoa(int(lv))
+}
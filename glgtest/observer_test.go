@@ -0,0 +1,173 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glgtest provides helpers for testing code which logs with glg
+package glgtest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kpango/glg"
+)
+
+// fakeTB records errors reported by AssertLogged
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, format)
+}
+
+func TestObserver(t *testing.T) {
+	g, o := New()
+	g.AddStdLevel("NOTICE", glg.WRITER, false)
+	g.SetLevelWriter(g.TagStringToLevel("NOTICE"), o)
+	g.Info("server started")
+	g.With("user", "alice").Warnf("login failed %d times", 3)
+	g.Error("connection refused")
+	g.CustomLog("NOTICE", "disk almost full")
+
+	tests := []struct {
+		name string
+		got  Entries
+		want []string
+	}{
+		{
+			name: "all",
+			got:  o.All(),
+			want: []string{"server started", "login failed 3 times", "connection refused", "disk almost full"},
+		},
+		{
+			name: "FilterLevel",
+			got:  o.FilterLevel(glg.WARN),
+			want: []string{"login failed 3 times"},
+		},
+		{
+			name: "FilterLevel of custom level",
+			got:  o.FilterLevel(g.TagStringToLevel("NOTICE")),
+			want: []string{"disk almost full"},
+		},
+		{
+			name: "FilterMessageContains",
+			got:  o.FilterMessageContains("o"),
+			want: []string{"login failed 3 times", "connection refused", "disk almost full"},
+		},
+		{
+			name: "FilterTag and FilterField",
+			got:  o.All().FilterTag("WARN").FilterField("user", "alice"),
+			want: []string{"login failed 3 times"},
+		},
+		{
+			name: "FilterField not matched",
+			got:  o.All().FilterField("user", "bob"),
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.Messages(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Messages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	warn := o.FilterLevel(glg.WARN)[0]
+	if warn.Tag != "WARN" || !strings.HasPrefix(warn.Caller, "observer_test.go:") || warn.Time.IsZero() {
+		t.Errorf("recorded entry = %+v", warn)
+	}
+	if v, ok := warn.Field("user"); !ok || v != "alice" {
+		t.Errorf("Entry.Field() = %v, %v, want alice, true", v, ok)
+	}
+	if got := warn.String(); got != "[WARN]: login failed 3 times user=alice" {
+		t.Errorf("Entry.String() = %q", got)
+	}
+
+	if taken := o.TakeAll(); len(taken) != 4 || o.Len() != 0 {
+		t.Errorf("TakeAll() = %d entries, Len() = %d after TakeAll", len(taken), o.Len())
+	}
+}
+
+func TestObserver_AssertLogged(t *testing.T) {
+	g, o := New()
+	g.Warn("cache miss")
+	tests := []struct {
+		name   string
+		lv     glg.LEVEL
+		substr string
+		want   bool
+	}{
+		{
+			name:   "logged",
+			lv:     glg.WARN,
+			substr: "miss",
+			want:   true,
+		},
+		{
+			name:   "other level",
+			lv:     glg.ERR,
+			substr: "miss",
+			want:   false,
+		},
+		{
+			name:   "other message",
+			lv:     glg.WARN,
+			substr: "hit",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := new(fakeTB)
+			if got := o.AssertLogged(tb, tt.lv, tt.substr); got != tt.want {
+				t.Errorf("AssertLogged() = %v, want %v", got, tt.want)
+			}
+			if failed := len(tb.errors) != 0; failed == tt.want {
+				t.Errorf("AssertLogged() reported errors %v", tb.errors)
+			}
+		})
+	}
+}
+
+func TestObserver_Clock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := NewClock(start)
+	g, o := New()
+	g.SetClock(c.Now).SetTimeLocation(time.UTC)
+	g.Info("first")
+	c.Add(time.Minute)
+	g.Info("second")
+	c.Set(start.Add(time.Hour))
+	g.Info("third")
+	want := []time.Time{start, start.Add(time.Minute), start.Add(time.Hour)}
+	for i, e := range o.All() {
+		if !e.Time.Equal(want[i]) {
+			t.Errorf("entry %d time = %v, want %v", i, e.Time, want[i])
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glgtest provides helpers for testing code which logs with glg
+package glgtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kpango/glg"
+)
+
+// TBWriter is io.Writer which writes each log line to testing.TB.Log,
+// so the output is shown with the test which logged it.
+// The lines written after the test has finished are discarded.
+type TBWriter struct {
+	mu   sync.RWMutex
+	tb   testing.TB
+	done bool
+}
+
+// NewTBWriter returns TBWriter of tb
+func NewTBWriter(tb testing.TB) *TBWriter {
+	w := &TBWriter{tb: tb}
+	tb.Cleanup(func() {
+		w.mu.Lock()
+		w.done = true
+		w.mu.Unlock()
+	})
+	return w
+}
+
+// NewTB returns glg instance which writes all levels to tb.Log without color
+func NewTB(tb testing.TB) *glg.Glg {
+	return glg.New().
+		SetMode(glg.WRITER).
+		DisableColor().
+		SetWriter(NewTBWriter(tb))
+}
+
+// Write writes b to tb.Log without the trailing newline
+func (w *TBWriter) Write(b []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.done {
+		w.tb.Log(strings.TrimSuffix(string(b), "\n"))
+	}
+	return len(b), nil
+}
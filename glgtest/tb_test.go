@@ -0,0 +1,61 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glgtest provides helpers for testing code which logs with glg
+package glgtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kpango/glg"
+)
+
+// logTB records the lines passed to Log
+type logTB struct {
+	testing.TB
+	lines    []string
+	cleanups []func()
+}
+
+func (l *logTB) Log(args ...interface{}) {
+	l.lines = append(l.lines, args[0].(string))
+}
+
+func (l *logTB) Cleanup(fn func()) {
+	l.cleanups = append(l.cleanups, fn)
+}
+
+func TestNewTB(t *testing.T) {
+	tb := new(logTB)
+	g := NewTB(tb).DisableTimestamp().SetLineTraceMode(glg.TraceLineNone)
+	g.Info("hello")
+	g.Warnf("%d items", 2)
+	for _, fn := range tb.cleanups {
+		fn()
+	}
+	g.Info("after test")
+	want := []string{"[INFO]:\thello", "[WARN]:\t2 items"}
+	if strings.Join(tb.lines, "|") != strings.Join(want, "|") {
+		t.Errorf("logged lines = %q, want %q", tb.lines, want)
+	}
+}
@@ -0,0 +1,167 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glgzap provides zapcore.Core which writes zap entries through glg
+package glgzap
+
+import (
+	"sort"
+
+	"github.com/kpango/glg"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core is zapcore.Core which writes entries to glg instance.
+// The entries are logged by the glg level mapped from the zap level,
+// so they are filtered, formatted and written by the settings of the glg level.
+type Core struct {
+	g      *glg.Glg
+	levels map[zapcore.Level]glg.LEVEL
+	fields []glg.Field
+	ns     string
+}
+
+// NewCore returns Core backed by g.
+// zap DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel, PanicLevel and FatalLevel
+// are mapped to DEBG, INFO, WARN, ERR, ERR, PANIC and FATAL.
+func NewCore(g *glg.Glg) *Core {
+	return &Core{
+		g: g,
+		levels: map[zapcore.Level]glg.LEVEL{
+			zapcore.DebugLevel:  glg.DEBG,
+			zapcore.InfoLevel:   glg.INFO,
+			zapcore.WarnLevel:   glg.WARN,
+			zapcore.ErrorLevel:  glg.ERR,
+			zapcore.DPanicLevel: glg.ERR,
+			zapcore.PanicLevel:  glg.PANIC,
+			zapcore.FatalLevel:  glg.FATAL,
+		},
+	}
+}
+
+// New returns zap.Logger which writes to g with the caller, opts are applied after zap.AddCaller
+func New(g *glg.Glg, opts ...zap.Option) *zap.Logger {
+	return zap.New(NewCore(g), append([]zap.Option{zap.AddCaller()}, opts...)...)
+}
+
+// MapLevel returns core which logs the entries of zap level zl as glg level lv
+func (c *Core) MapLevel(zl zapcore.Level, lv glg.LEVEL) *Core {
+	levels := make(map[zapcore.Level]glg.LEVEL, len(c.levels)+1)
+	for k, v := range c.levels {
+		levels[k] = v
+	}
+	levels[zl] = lv
+	nc := *c
+	nc.levels = levels
+	return &nc
+}
+
+// Level returns glg level for zap level, the levels which are not mapped use the nearest mapped level below them,
+// or the lowest mapped level.
+func (c *Core) Level(zl zapcore.Level) glg.LEVEL {
+	if lv, ok := c.levels[zl]; ok {
+		return lv
+	}
+	zls := make([]zapcore.Level, 0, len(c.levels))
+	for l := range c.levels {
+		zls = append(zls, l)
+	}
+	if len(zls) == 0 {
+		return glg.UNKNOWN
+	}
+	sort.Slice(zls, func(i, j int) bool {
+		return zls[i] < zls[j]
+	})
+	for i := len(zls) - 1; i >= 0; i-- {
+		if zls[i] <= zl {
+			return c.levels[zls[i]]
+		}
+	}
+	return c.levels[zls[0]]
+}
+
+// Enabled reports whether the glg level mapped from zl is logging
+func (c *Core) Enabled(zl zapcore.Level) bool {
+	return c.g.GetCurrentMode(c.Level(zl)) != glg.NONE
+}
+
+// With returns core which attaches fields to every entry
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	nc := *c
+	nc.fields, nc.ns = appendFields(c.fields[:len(c.fields):len(c.fields)], c.ns, fields)
+	return &nc
+}
+
+// Check adds the core to ce when the entry level is enabled
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write logs ent with the core and entry fields, the logger name and the stack trace are logged as
+// "logger" and "stacktrace" fields.
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	fs := make([]glg.Field, 0, len(c.fields)+len(fields)+2)
+	if ent.LoggerName != "" {
+		fs = append(fs, glg.Field{Key: "logger", Value: ent.LoggerName})
+	}
+	fs = append(fs, c.fields...)
+	fs, _ = appendFields(fs, c.ns, fields)
+	if ent.Stack != "" {
+		fs = append(fs, glg.Field{Key: "stacktrace", Value: ent.Stack})
+	}
+	var pc uintptr
+	if ent.Caller.Defined && ent.Caller.PC != 0 {
+		// zap records the pc of the call instruction, glg expects the return address
+		pc = ent.Caller.PC + 1
+	}
+	return c.g.Emit(c.Level(ent.Level), pc, fs, ent.Message)
+}
+
+// Sync writes the entries queued by glg async logging and syncs the writers of glg
+func (c *Core) Sync() error {
+	return c.g.Sync()
+}
+
+// appendFields appends zap fields to fs in order, the keys of the fields after zap.Namespace are qualified by the namespace
+func appendFields(fs []glg.Field, ns string, fields []zapcore.Field) ([]glg.Field, string) {
+	for _, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			ns += f.Key + "."
+			continue
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		keys := make([]string, 0, len(enc.Fields))
+		for k := range enc.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fs = append(fs, glg.Field{Key: ns + k, Value: enc.Fields[k]})
+		}
+	}
+	return fs, ns
+}
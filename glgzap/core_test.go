@@ -0,0 +1,147 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glgzap provides zapcore.Core which writes zap entries through glg
+package glgzap
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/kpango/glg"
+	"github.com/kpango/glg/glgtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCore(t *testing.T) {
+	g, o := glgtest.New()
+	g.SetLevel(glg.INFO)
+	l := New(g).Named("db").With(zap.String("component", "pool"))
+	l.Debug("filtered")
+	l.Info("connected", zap.Int("conns", 3))
+	l.Warn("slow query", zap.Namespace("query"), zap.String("sql", "SELECT 1"), zap.Duration("took", 0))
+	l.Error("failed", zap.Error(errors.New("timeout")))
+
+	want := []glgtest.Entry{
+		{
+			Level:   glg.INFO,
+			Tag:     "INFO",
+			Message: "connected",
+			Fields: []glg.Field{
+				{Key: "logger", Value: "db"},
+				{Key: "component", Value: "pool"},
+				{Key: "conns", Value: int64(3)},
+			},
+		},
+		{
+			Level:   glg.WARN,
+			Tag:     "WARN",
+			Message: "slow query",
+			Fields: []glg.Field{
+				{Key: "logger", Value: "db"},
+				{Key: "component", Value: "pool"},
+				{Key: "query.sql", Value: "SELECT 1"},
+				{Key: "query.took", Value: time.Duration(0)},
+			},
+		},
+		{
+			Level:   glg.ERR,
+			Tag:     "ERR",
+			Message: "failed",
+			Fields: []glg.Field{
+				{Key: "logger", Value: "db"},
+				{Key: "component", Value: "pool"},
+				{Key: "error", Value: "timeout"},
+			},
+		},
+	}
+	got := o.All()
+	if len(got) != len(want) {
+		t.Fatalf("logged %d entries, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Level != want[i].Level || got[i].Tag != want[i].Tag || got[i].Message != want[i].Message ||
+			!reflect.DeepEqual(got[i].Fields, want[i].Fields) {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+		if !regexp.MustCompile(`^core_test\.go:\d+$`).MatchString(got[i].Caller) {
+			t.Errorf("entry %d caller = %q", i, got[i].Caller)
+		}
+	}
+}
+
+func TestCore_Level(t *testing.T) {
+	c := NewCore(glg.New()).MapLevel(zapcore.DPanicLevel, glg.FAIL)
+	tests := []struct {
+		name string
+		zl   zapcore.Level
+		want glg.LEVEL
+	}{
+		{
+			name: "mapped",
+			zl:   zapcore.WarnLevel,
+			want: glg.WARN,
+		},
+		{
+			name: "remapped",
+			zl:   zapcore.DPanicLevel,
+			want: glg.FAIL,
+		},
+		{
+			name: "panic",
+			zl:   zapcore.PanicLevel,
+			want: glg.PANIC,
+		},
+		{
+			name: "below lowest",
+			zl:   zapcore.DebugLevel - 2,
+			want: glg.DEBG,
+		},
+		{
+			name: "above highest",
+			zl:   zapcore.FatalLevel + 3,
+			want: glg.FATAL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Level(tt.zl); got != tt.want {
+				t.Errorf("Core.Level() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCore_Format(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := glg.New().SetMode(glg.WRITER).SetWriter(buf).DisableTimestamp().EnableJSON()
+	New(g).Info("msg", zap.Bool("ok", true))
+	want := `{"level":"INFO","detail":"msg","ok":true}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -7,9 +7,7 @@ require (
 	github.com/kpango/fastime v1.1.9
 	github.com/sirupsen/logrus v1.9.3
 	go.uber.org/zap v1.26.0
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8
 )
 
-require (
-	go.uber.org/multierr v1.10.0 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
-)
+require go.uber.org/multierr v1.10.0 // indirect
@@ -0,0 +1,174 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Record is log entry passed to hooks
+type Record struct {
+	Time    time.Time
+	Level   LEVEL
+	Tag     string
+	Caller  string
+	Message string
+	Fields  []Field
+}
+
+// Hook is called before the log entry is written.
+// Fire receives a copy of the record and returns the record to be written,
+// Message, Caller and Fields changed by the hook are written.
+// Returning ErrSkipRecord vetoes the entry, the other errors are passed to the error handler
+// and the entry is written without the changes of the hook.
+type Hook interface {
+	Fire(rec Record) (Record, error)
+}
+
+// HookFunc is function type of Hook
+type HookFunc func(rec Record) (Record, error)
+
+// Fire calls f(rec)
+func (f HookFunc) Fire(rec Record) (Record, error) {
+	return f(rec)
+}
+
+// ErrSkipRecord is returned by Hook to veto the log entry
+var ErrSkipRecord = errors.New("glg: record skipped by hook")
+
+type levelHook struct {
+	levels []LEVEL
+	hook   Hook
+}
+
+// AddHook registers hook fired for the levels, empty levels means all levels.
+// Hooks are fired in the registered order.
+func (g *Glg) AddHook(levels []LEVEL, hook Hook) *Glg {
+	if hook == nil {
+		return g
+	}
+	lh := levelHook{
+		levels: append([]LEVEL(nil), levels...),
+		hook:   hook,
+	}
+	for {
+		old := g.hooks.Load()
+		var hs []levelHook
+		if old != nil {
+			hs = make([]levelHook, len(*old), len(*old)+1)
+			copy(hs, *old)
+		}
+		hs = append(hs, lh)
+		if g.hooks.CompareAndSwap(old, &hs) {
+			return g
+		}
+	}
+}
+
+// AddHook registers hook fired for the levels, empty levels means all levels
+func AddHook(levels []LEVEL, hook Hook) *Glg {
+	return glg.AddHook(levels, hook)
+}
+
+// SetErrorHandler sets the handler of the errors which cannot be returned to the caller
+// such as hook errors, nil handler restores the default handler which prints to stderr.
+func (g *Glg) SetErrorHandler(fn func(err error)) *Glg {
+	if fn == nil {
+		g.errHandler.Store(nil)
+		return g
+	}
+	g.errHandler.Store(&fn)
+	return g
+}
+
+// handleError passes err to the error handler
+func (g *Glg) handleError(err error) {
+	if err == nil {
+		return
+	}
+	if fn := g.errHandler.Load(); fn != nil {
+		(*fn)(err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "glg:", err)
+}
+
+func (lh levelHook) match(level LEVEL) bool {
+	if len(lh.levels) == 0 {
+		return true
+	}
+	for _, lv := range lh.levels {
+		if lv == level {
+			return true
+		}
+	}
+	return false
+}
+
+// fireHooks fires hooks for the level of e with the message and time of e, then applies the changes of the hooks to e.
+// It returns false when the entry is vetoed.
+func (g *Glg) fireHooks(hs []levelHook, log *logger, e *Entry, now time.Time) bool {
+	var (
+		rec   Record
+		fired bool
+	)
+	for _, lh := range hs {
+		if !lh.match(e.Level) {
+			continue
+		}
+		if !fired {
+			rec = Record{
+				Time:    now,
+				Level:   e.Level,
+				Tag:     e.Tag,
+				Caller:  e.Caller,
+				Message: e.message(),
+				Fields:  e.Fields,
+			}
+			fired = true
+		}
+		in := rec
+		in.Fields = append([]Field(nil), rec.Fields...)
+		out, err := lh.hook.Fire(in)
+		switch {
+		case errors.Is(err, ErrSkipRecord):
+			return false
+		case err != nil:
+			g.handleError(fmt.Errorf("hook error for %s level: %w", log.tag, err))
+		default:
+			rec = out
+		}
+	}
+	if !fired {
+		return true
+	}
+	if rec.Message != e.Message {
+		e.Message, e.Values = rec.Message, nil
+	}
+	e.Caller, e.Fields = rec.Caller, rec.Fields
+	return true
+}
@@ -0,0 +1,229 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGlg_AddHook(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []LEVEL
+		hook   HookFunc
+		log    func(g *Glg) error
+		want   string
+	}{
+		{
+			name: "mutate message and fields",
+			hook: func(rec Record) (Record, error) {
+				rec.Message = strings.ToUpper(rec.Message)
+				rec.Fields = append(rec.Fields, Field{Key: "hooked", Value: true})
+				return rec, nil
+			},
+			log:  func(g *Glg) error { return g.Info("hello") },
+			want: "[INFO]:\tHELLO\thooked=true\n",
+		},
+		{
+			name: "veto",
+			hook: func(rec Record) (Record, error) {
+				return rec, ErrSkipRecord
+			},
+			log:  func(g *Glg) error { return g.Info("hello") },
+			want: "",
+		},
+		{
+			name:   "other level is not hooked",
+			levels: []LEVEL{ERR},
+			hook: func(rec Record) (Record, error) {
+				return rec, ErrSkipRecord
+			},
+			log:  func(g *Glg) error { return g.Info("hello") },
+			want: "[INFO]:\thello\n",
+		},
+		{
+			name: "unchanged message keeps values",
+			hook: func(rec Record) (Record, error) {
+				return rec, nil
+			},
+			log:  func(g *Glg) error { return g.Infof("%d%%", 100) },
+			want: "[INFO]:\t100%\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().AddHook(tt.levels, tt.hook)
+			if err := tt.log(g); err != nil {
+				t.Error(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Glg.AddHook() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_AddHookRecord(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		recs []Record
+	)
+	g := New().SetMode(NONE).SetLevelMode(ERR, WRITER).SetWriter(new(bytes.Buffer)).
+		SetLevelLineTraceMode(ERR, TraceLineShort).
+		AddHook([]LEVEL{ERR}, HookFunc(func(rec Record) (Record, error) {
+			mu.Lock()
+			recs = append(recs, rec)
+			mu.Unlock()
+			rec.Fields[0].Value = "mutated"
+			return rec, nil
+		})).
+		AddHook(nil, HookFunc(func(rec Record) (Record, error) {
+			mu.Lock()
+			recs = append(recs, rec)
+			mu.Unlock()
+			return rec, nil
+		}))
+	parent := g.With("k", "v")
+	parent.Error("failed")
+	if len(recs) != 2 {
+		t.Fatalf("hooks fired %d times, want 2", len(recs))
+	}
+	rec := recs[0]
+	if rec.Level != ERR || rec.Tag != "ERR" || rec.Message != "failed" || rec.Time.IsZero() ||
+		!strings.HasPrefix(rec.Caller, "hook_test.go:") {
+		t.Errorf("Glg.AddHook() record = %+v", rec)
+	}
+	if recs[1].Fields[0].Value != "mutated" {
+		t.Errorf("hooks are not chained: %+v", recs[1])
+	}
+	if parent.Fields()[0].Value != "v" {
+		t.Error("hook mutated logger fields")
+	}
+}
+
+func TestGlg_AddHookEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     bool
+		hook     HookFunc
+		wantMsg  string
+		wantLine string
+	}{
+		{
+			name:     "text message",
+			hook:     func(rec Record) (Record, error) { return rec, nil },
+			wantMsg:  "a b",
+			wantLine: "[INFO]:\ta b\n",
+		},
+		{
+			name:     "json message",
+			json:     true,
+			hook:     func(rec Record) (Record, error) { return rec, nil },
+			wantMsg:  "a b",
+			wantLine: `"detail":["a","b"]`,
+		},
+		{
+			name: "message set to the same text keeps the values",
+			json: true,
+			hook: func(rec Record) (Record, error) {
+				rec.Message = "a b"
+				return rec, nil
+			},
+			wantMsg:  "a b",
+			wantLine: `"detail":["a","b"]`,
+		},
+		{
+			name: "changed message",
+			json: true,
+			hook: func(rec Record) (Record, error) {
+				rec.Message = "c"
+				return rec, nil
+			},
+			wantMsg:  "a b",
+			wantLine: `"detail":"c"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				rec      Record
+				written  time.Time
+				buf      = new(bytes.Buffer)
+				encoder  Encoder
+				recorder = EncoderFunc(func(b *bytes.Buffer, e *Entry) error {
+					written = e.Time
+					return encoder.EncodeEntry(b, e)
+				})
+			)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).
+				AddHook(nil, HookFunc(func(r Record) (Record, error) {
+					rec = r
+					return tt.hook(r)
+				}))
+			encoder = TextEncoder
+			if tt.json {
+				g.EnableJSON()
+				encoder = JSONEncoder
+			}
+			g.SetWriterEncoder(buf, recorder)
+			if err := g.Info("a", "b"); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Message != tt.wantMsg {
+				t.Errorf("hook message = %q, want %q", rec.Message, tt.wantMsg)
+			}
+			if !rec.Time.Equal(written) {
+				t.Errorf("hook time = %v, written entry time = %v", rec.Time, written)
+			}
+			if !strings.Contains(buf.String(), tt.wantLine) {
+				t.Errorf("written entry = %q, want %q", buf.String(), tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestGlg_SetErrorHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var got error
+	hookErr := errors.New("reporter unavailable")
+	g := New().SetMode(WRITER).SetWriter(buf).
+		SetErrorHandler(func(err error) { got = err }).
+		AddHook(nil, HookFunc(func(rec Record) (Record, error) {
+			rec.Message = "changed"
+			return rec, hookErr
+		}))
+	if err := g.Warn("original"); err != nil {
+		t.Error(err)
+	}
+	if !errors.Is(got, hookErr) {
+		t.Errorf("error handler got %v, want %v", got, hookErr)
+	}
+	if !strings.Contains(buf.String(), "original") {
+		t.Errorf("failed hook changed entry: %v", buf.String())
+	}
+}
@@ -0,0 +1,204 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// DefaultJournalSocket is the native protocol socket of systemd-journald
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// JournalWriter is io.WriteCloser and Encoder which sends log entries to systemd-journald by the native protocol.
+// The entries are encoded by JournalWriter itself, so it should be set by SetWriter, SetLevelWriter,
+// AddWriter or AddLevelWriter directly instead of being wrapped by another writer.
+//
+// The entry has MESSAGE, PRIORITY mapped from the level like SyslogWriter, SYSLOG_IDENTIFIER, GLG_LEVEL,
+// CODE_FILE and CODE_LINE from the line trace, and the fields whose names are upper-cased.
+// Entries too large for a datagram are passed by a sealed memfd on Linux.
+type JournalWriter struct {
+	mu         sync.Mutex
+	addr       *net.UnixAddr
+	conn       *net.UnixConn
+	identifier string
+	priorities map[LEVEL]SyslogSeverity
+}
+
+// NewJournalWriter returns journald writer which sends entries to the unix datagram socket at path.
+// Empty path is DefaultJournalSocket.
+func NewJournalWriter(path string) *JournalWriter {
+	if path == "" {
+		path = DefaultJournalSocket
+	}
+	return &JournalWriter{
+		addr:       &net.UnixAddr{Name: path, Net: "unixgram"},
+		identifier: filepath.Base(os.Args[0]),
+	}
+}
+
+// SetIdentifier sets SYSLOG_IDENTIFIER of the entries, the program name is default
+func (j *JournalWriter) SetIdentifier(id string) *JournalWriter {
+	j.mu.Lock()
+	j.identifier = id
+	j.mu.Unlock()
+	return j
+}
+
+// SetPriority maps glg level to journal PRIORITY, it is used for custom levels which are SeverityInfo by default
+func (j *JournalWriter) SetPriority(lv LEVEL, priority SyslogSeverity) *JournalWriter {
+	j.mu.Lock()
+	if j.priorities == nil {
+		j.priorities = make(map[LEVEL]SyslogSeverity)
+	}
+	j.priorities[lv] = priority
+	j.mu.Unlock()
+	return j
+}
+
+// EncodeEntry encodes e in the journal native protocol
+func (j *JournalWriter) EncodeEntry(b *bytes.Buffer, e *Entry) error {
+	j.mu.Lock()
+	priority, ok := j.priorities[e.Level]
+	if !ok {
+		priority = levelSeverity(e.Level)
+	}
+	identifier := j.identifier
+	j.mu.Unlock()
+
+	appendJournalField(b, "MESSAGE", e.Message)
+	appendJournalField(b, "PRIORITY", strconv.Itoa(int(priority)))
+	if identifier != "" {
+		appendJournalField(b, "SYSLOG_IDENTIFIER", identifier)
+	}
+	appendJournalField(b, "GLG_LEVEL", e.Tag)
+	if len(e.Caller) != 0 {
+		file, line := splitCaller(e.Caller)
+		appendJournalField(b, "CODE_FILE", file)
+		if line != "" {
+			appendJournalField(b, "CODE_LINE", line)
+		}
+	}
+	for _, f := range e.Fields {
+		appendJournalField(b, journalFieldName(f.Key), fieldValueString(f.Value))
+	}
+	return nil
+}
+
+// appendJournalField writes the field as NAME=value line, or in the binary form when value has newline
+func appendJournalField(b *bytes.Buffer, name, val string) {
+	b.WriteString(name)
+	if strings.IndexByte(val, '\n') < 0 {
+		b.WriteByte('=')
+		b.WriteString(val)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	b.Write(binary.LittleEndian.AppendUint64(b.AvailableBuffer(), uint64(len(val))))
+	b.WriteString(val)
+	b.WriteByte('\n')
+}
+
+// journalFieldName converts key to journal field name which consists of A-Z, 0-9 and '_',
+// does not start with '_' or digit, and is at most 64 characters
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z':
+			return r - 'a' + 'A'
+		case 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		name = "FIELD_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// splitCaller splits line trace such as "file.go:12" or "https://.../file.go#L12" to file and line
+func splitCaller(caller string) (file, line string) {
+	if i := strings.LastIndex(caller, "#L"); i >= 0 {
+		return caller[:i], caller[i+2:]
+	}
+	if i := strings.LastIndexByte(caller, ':'); i >= 0 {
+		if _, err := strconv.Atoi(caller[i+1:]); err == nil {
+			return caller[:i], caller[i+1:]
+		}
+	}
+	return caller, ""
+}
+
+// Write sends b as a journal entry
+func (j *JournalWriter) Write(b []byte) (n int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for retry := 0; retry < 2; retry++ {
+		if j.conn == nil {
+			// unconnected socket is used because the file descriptor cannot be sent by connected datagram socket
+			j.conn, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+			if err != nil {
+				j.conn = nil
+				return 0, fmt.Errorf("failed to open journal socket: %w", err)
+			}
+		}
+		_, err = j.conn.WriteToUnix(b, j.addr)
+		if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+			err = sendJournalFd(j.conn, j.addr, b)
+		}
+		if err == nil {
+			return len(b), nil
+		}
+		j.conn.Close()
+		j.conn = nil
+	}
+	return 0, fmt.Errorf("failed to write to journal %s: %w", j.addr.Name, err)
+}
+
+// Close closes the connection
+func (j *JournalWriter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conn == nil {
+		return nil
+	}
+	err := j.conn.Close()
+	j.conn = nil
+	return err
+}
@@ -0,0 +1,174 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// parseJournalEntry decodes the journal native protocol
+func parseJournalEntry(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(b) != 0 {
+		i := bytes.IndexAny(b, "=\n")
+		if i < 0 {
+			t.Fatalf("invalid entry %q", b)
+		}
+		name := string(b[:i])
+		if b[i] == '=' {
+			end := bytes.IndexByte(b, '\n')
+			fields[name] = string(b[i+1 : end])
+			b = b[end+1:]
+			continue
+		}
+		n := binary.LittleEndian.Uint64(b[i+1 : i+9])
+		fields[name] = string(b[i+9 : i+9+int(n)])
+		if b[i+9+int(n)] != '\n' {
+			t.Fatalf("binary field %s is not terminated by newline", name)
+		}
+		b = b[i+10+int(n):]
+	}
+	return fields
+}
+
+func TestJournalWriter_EncodeEntry(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(j *JournalWriter)
+		entry Entry
+		want  map[string]string
+	}{
+		{
+			name:  "message with caller and fields",
+			entry: Entry{Level: WARN, Tag: "WARN", Caller: "main.go:12", Message: "disk full", Fields: []Field{{Key: "request-id", Value: 42}, {Key: "_secret", Value: "x"}, {Key: "1st", Value: true}}},
+			want: map[string]string{
+				"MESSAGE":           "disk full",
+				"PRIORITY":          "4",
+				"SYSLOG_IDENTIFIER": "app",
+				"GLG_LEVEL":         "WARN",
+				"CODE_FILE":         "main.go",
+				"CODE_LINE":         "12",
+				"REQUEST_ID":        "42",
+				"SECRET":            "x",
+				"FIELD_1ST":         "true",
+			},
+		},
+		{
+			name:  "multiline message and url caller",
+			entry: Entry{Level: ERR, Tag: "ERR", Caller: "https://github.com/kpango/glg/blob/main/glg.go#L100", Message: "line1\nline2"},
+			want: map[string]string{
+				"MESSAGE":           "line1\nline2",
+				"PRIORITY":          "3",
+				"SYSLOG_IDENTIFIER": "app",
+				"GLG_LEVEL":         "ERR",
+				"CODE_FILE":         "https://github.com/kpango/glg/blob/main/glg.go",
+				"CODE_LINE":         "100",
+			},
+		},
+		{
+			name:  "custom level priority without identifier",
+			setup: func(j *JournalWriter) { j.SetPriority(LEVEL(20), SeverityAlert).SetIdentifier("") },
+			entry: Entry{Level: LEVEL(20), Tag: "ALERT", Message: "msg"},
+			want: map[string]string{
+				"MESSAGE":   "msg",
+				"PRIORITY":  "1",
+				"GLG_LEVEL": "ALERT",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := NewJournalWriter("").SetIdentifier("app")
+			if tt.setup != nil {
+				tt.setup(j)
+			}
+			b := new(bytes.Buffer)
+			if err := j.EncodeEntry(b, &tt.entry); err != nil {
+				t.Fatal(err)
+			}
+			if got := parseJournalEntry(t, b.Bytes()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EncodeEntry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// listenJournal returns unixgram listener which stands in for journald
+func listenJournal(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn, path
+}
+
+func TestJournalWriter_Write(t *testing.T) {
+	conn, path := listenJournal(t)
+	j := NewJournalWriter(path).SetIdentifier("app")
+	defer j.Close()
+
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineShort).SetLevelWriter(INFO, j)
+	if err := g.With("user", "john").Info("hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parseJournalEntry(t, buf[:n])
+	for k, want := range map[string]string{
+		"MESSAGE":   "hello",
+		"PRIORITY":  "6",
+		"GLG_LEVEL": "INFO",
+		"CODE_FILE": "journald_test.go",
+		"USER":      "john",
+	} {
+		if got[k] != want {
+			t.Errorf("%s = %q, want %q", k, got[k], want)
+		}
+	}
+	if got["CODE_LINE"] == "" {
+		t.Error("CODE_LINE is empty")
+	}
+}
+
+func TestJournalWriter_WriteError(t *testing.T) {
+	j := NewJournalWriter(filepath.Join(t.TempDir(), "missing"))
+	if _, err := j.Write([]byte("MESSAGE=msg\n")); err == nil {
+		t.Error("Write() error = nil, want error")
+	}
+}
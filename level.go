@@ -0,0 +1,164 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrLevelExhausted is returned when all LEVEL values for custom levels are in use
+var ErrLevelExhausted = errors.New("glg: no level left for custom level")
+
+// defaultLevelAliases are tags which TagStringToLevel converts to the standard levels besides their own tags
+var defaultLevelAliases = map[LEVEL][]string{
+	DEBG:  {"DBG", "DEBUG", "D"},
+	TRACE: {"TRC", "TRA", "TR", "T"},
+	PRINT: {"PNT", "P"},
+	LOG:   {"LO", "LG", "L"},
+	INFO:  {"IFO", "INF", "I"},
+	OK:    {"O", "K"},
+	WARN:  {"WARNING", "WRN", "W"},
+	ERR:   {"ERROR", "ER", "E"},
+	FAIL:  {"FAILED", "FI"},
+	FATAL: {"FAT", "FL", "F"},
+	PANIC: {"PNC", "PC"},
+}
+
+// RegisterLevel adds log level ordered by sev which writes to std in STD mode and returns it.
+// ErrLevelExhausted is returned when no LEVEL value is left, the values of removed levels are reused.
+func (g *Glg) RegisterLevel(tag string, std io.Writer, mode MODE, isColor bool, sev Severity) (LEVEL, error) {
+	return g.addLevel(tag, mode, isColor, std, &sev)
+}
+
+// RemoveLevel removes the custom level and its aliases, the standard levels cannot be removed
+func (g *Glg) RemoveLevel(lv LEVEL) *Glg {
+	if lv <= FATAL || lv >= PANIC {
+		return g
+	}
+	g.levelMu.Lock()
+	defer g.levelMu.Unlock()
+	g.logger.Delete(lv)
+	g.levelMap.Range(func(tag string, lev LEVEL) bool {
+		if lev == lv {
+			g.levelMap.Delete(tag)
+		}
+		return true
+	})
+	return g
+}
+
+// RenameLevel changes the tag of the level, TagStringToLevel converts the new tag instead of the old one.
+// The names of the standard levels such as INFO are still converted to them.
+func (g *Glg) RenameLevel(lv LEVEL, tag string) *Glg {
+	g.setTag(lv, strings.ToUpper(tag))
+	return g
+}
+
+// AddLevelAlias makes TagStringToLevel convert alias to the level,
+// an alias overrides the same tag of another level.
+func (g *Glg) AddLevelAlias(alias string, lv LEVEL) *Glg {
+	alias = strings.TrimSpace(strings.ToUpper(alias))
+	if alias == "" {
+		return g
+	}
+	g.levelMu.Lock()
+	defer g.levelMu.Unlock()
+	if _, ok := g.logger.Load(lv); ok {
+		g.levelMap.Store(alias, lv)
+	}
+	return g
+}
+
+// addLevel adds level ordered by sev, nil sev orders it by the LEVEL value
+func (g *Glg) addLevel(tag string, mode MODE, isColor bool, std io.Writer, sev *Severity) (LEVEL, error) {
+	tag = strings.ToUpper(tag)
+	g.levelMu.Lock()
+	defer g.levelMu.Unlock()
+	lev, ok := g.nextLevel()
+	if !ok {
+		return UNKNOWN, fmt.Errorf("%w: %s", ErrLevelExhausted, tag)
+	}
+	severity := SeverityOf(lev)
+	if sev != nil {
+		severity = *sev
+	}
+	l := &logger{
+		severity: severity,
+		writer:   nil,
+		std:      std,
+		color:    Colorless,
+		isColor:  isColor,
+		mode:     mode,
+		prevMode: mode,
+		tag:      tag,
+		rawtag:   []byte(lsep + tag + sep),
+		stats:    new(levelStats),
+	}
+	l.updateMode()
+	g.updateTimestamp(l)
+	g.updateDestinations(l)
+	g.logger.Store(lev, l)
+	g.levelMap.Store(tag, lev)
+	return lev, nil
+}
+
+// nextLevel returns unused LEVEL value, the values after the last added level are used before the removed ones
+func (g *Glg) nextLevel() (LEVEL, bool) {
+	if next := atomic.LoadUint32(g.levelCounter) + 1; next < uint32(PANIC) {
+		atomic.StoreUint32(g.levelCounter, next)
+		return LEVEL(next), true
+	}
+	for lev := FATAL + 1; lev < PANIC; lev++ {
+		if _, ok := g.logger.Load(lev); !ok {
+			return lev, true
+		}
+	}
+	return UNKNOWN, false
+}
+
+// setTag changes the tag of the level and moves its levelMap entry to the new tag,
+// the standard levels keep their own names.
+func (g *Glg) setTag(lv LEVEL, tag string) {
+	g.levelMu.Lock()
+	defer g.levelMu.Unlock()
+	l, ok := g.logger.Load(lv)
+	if !ok {
+		return
+	}
+	old := strings.TrimSpace(strings.ToUpper(l.tag))
+	if lev, ok := g.levelMap.Load(old); ok && lev == lv && old != lv.String() {
+		g.levelMap.Delete(old)
+	}
+	nl := *l
+	nl.tag = tag
+	nl.rawtag = []byte(lsep + tag + sep)
+	g.logger.Store(lv, &nl)
+	if key := strings.TrimSpace(strings.ToUpper(tag)); key != "" {
+		g.levelMap.Store(key, lv)
+	}
+}
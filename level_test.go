@@ -0,0 +1,234 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestGlg_RemoveLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		remove   func(g *Glg) LEVEL
+		wantLost bool
+	}{
+		{
+			name: "custom level",
+			tag:  "AUDIT",
+			remove: func(g *Glg) LEVEL {
+				lv := g.TagStringToLevel("AUDIT")
+				g.AddLevelAlias("AUD", lv).RemoveLevel(lv)
+				return lv
+			},
+			wantLost: true,
+		},
+		{
+			name: "standard level is kept",
+			tag:  "INFO",
+			remove: func(g *Glg) LEVEL {
+				g.RemoveLevel(INFO)
+				return INFO
+			},
+			wantLost: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New().AddStdLevel("AUDIT", WRITER, false)
+			lv := tt.remove(g)
+			_, ok := g.logger.Load(lv)
+			if ok == tt.wantLost {
+				t.Errorf("logger of %s exists = %v", tt.tag, ok)
+			}
+			for _, tag := range []string{tt.tag, "AUD"} {
+				if got := g.TagStringToLevel(tag); tt.wantLost && got != UNKNOWN {
+					t.Errorf("TagStringToLevel(%q) = %v, want UNKNOWN", tag, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGlg_RegisterLevel(t *testing.T) {
+	g := New()
+	var last LEVEL
+	for i := FATAL + 1; i < PANIC; i++ {
+		lv, err := g.RegisterLevel("L"+strconv.Itoa(int(i)), os.Stdout, STD, false, SeverityOf(i))
+		if err != nil {
+			t.Fatalf("RegisterLevel() error = %v", err)
+		}
+		if lv != i {
+			t.Fatalf("RegisterLevel() = %v, want %v", lv, i)
+		}
+		last = lv
+	}
+	if last != PANIC-1 {
+		t.Fatalf("last level = %v, want %v", last, PANIC-1)
+	}
+
+	if lv, err := g.RegisterLevel("OVER", os.Stdout, STD, false, 0); !errors.Is(err, ErrLevelExhausted) || lv != UNKNOWN {
+		t.Errorf("RegisterLevel() = %v, %v, want UNKNOWN, %v", lv, err, ErrLevelExhausted)
+	}
+	if got := g.TagStringToLevel("OVER"); got != UNKNOWN {
+		t.Errorf("TagStringToLevel(OVER) = %v, want UNKNOWN", got)
+	}
+
+	var handled error
+	g.SetErrorHandler(func(err error) { handled = err }).AddStdLevel("OVER", STD, false)
+	if !errors.Is(handled, ErrLevelExhausted) {
+		t.Errorf("AddStdLevel() handled error = %v, want %v", handled, ErrLevelExhausted)
+	}
+
+	reused := g.TagStringToLevel("L20")
+	g.RemoveLevel(reused)
+	lv, err := g.RegisterLevel("REUSED", os.Stdout, STD, false, 0)
+	if err != nil || lv != reused {
+		t.Errorf("RegisterLevel() = %v, %v, want %v, nil", lv, err, reused)
+	}
+	if got := g.TagStringToLevel("REUSED"); got != reused {
+		t.Errorf("TagStringToLevel(REUSED) = %v, want %v", got, reused)
+	}
+}
+
+func TestGlg_RenameLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		lv      func(g *Glg) LEVEL
+		rename  func(g *Glg, lv LEVEL) *Glg
+		oldTag  string
+		wantOld func(lv LEVEL) LEVEL
+		newTag  string
+		wantOut string
+	}{
+		{
+			name:    "custom level",
+			lv:      func(g *Glg) LEVEL { return g.TagStringToLevel("AUDIT") },
+			rename:  func(g *Glg, lv LEVEL) *Glg { return g.RenameLevel(lv, "security") },
+			oldTag:  "AUDIT",
+			wantOld: func(LEVEL) LEVEL { return UNKNOWN },
+			newTag:  "SECURITY",
+			wantOut: "[SECURITY]:\tmsg\n",
+		},
+		{
+			name:    "standard level keeps name and aliases",
+			lv:      func(*Glg) LEVEL { return INFO },
+			rename:  func(g *Glg, lv LEVEL) *Glg { return g.RenameLevel(lv, "NOTE") },
+			oldTag:  "INFO",
+			wantOld: func(lv LEVEL) LEVEL { return lv },
+			newTag:  "NOTE",
+			wantOut: "[NOTE]:\tmsg\n",
+		},
+		{
+			name:    "SetPrefix",
+			lv:      func(*Glg) LEVEL { return PRINT },
+			rename:  func(g *Glg, lv LEVEL) *Glg { return g.SetPrefix(lv, "Out") },
+			oldTag:  "PRINT",
+			wantOld: func(lv LEVEL) LEVEL { return lv },
+			newTag:  "OUT",
+			wantOut: "[Out]:\tmsg\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().
+				AddStdLevel("AUDIT", WRITER, false).
+				SetMode(WRITER).
+				SetWriter(buf).
+				DisableTimestamp().
+				SetLineTraceMode(TraceLineNone)
+			lv := tt.lv(g)
+			tt.rename(g, lv)
+			if got, want := g.TagStringToLevel(tt.oldTag), tt.wantOld(lv); got != want {
+				t.Errorf("TagStringToLevel(%q) = %v, want %v", tt.oldTag, got, want)
+			}
+			if got := g.TagStringToLevel(tt.newTag); got != lv {
+				t.Errorf("TagStringToLevel(%q) = %v, want %v", tt.newTag, got, lv)
+			}
+			for _, alias := range defaultLevelAliases[lv] {
+				if got := g.TagStringToLevel(alias); got != lv {
+					t.Errorf("TagStringToLevel(%q) = %v, want %v", alias, got, lv)
+				}
+			}
+			if err := g.CustomLog(tt.newTag, "msg"); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestGlg_AddLevelAlias(t *testing.T) {
+	g := New().AddStdLevel("NOTICE", STD, false)
+	notice := g.TagStringToLevel("NOTICE")
+	g.AddLevelAlias("note", notice).AddLevelAlias(" N ", notice).AddLevelAlias("W", notice).AddLevelAlias("X", UNKNOWN)
+	tests := []struct {
+		tag  string
+		want LEVEL
+	}{
+		{tag: "NOTE", want: notice},
+		{tag: "n", want: notice},
+		{tag: "w", want: notice},
+		{tag: "WRN", want: WARN},
+		{tag: "DBG", want: DEBG},
+		{tag: "X", want: UNKNOWN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := g.TagStringToLevel(tt.tag); got != tt.want {
+				t.Errorf("TagStringToLevel(%q) = %v, want %v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstantValues(t *testing.T) {
+	g := New().AddStdLevel("AUDIT", STD, false)
+	tests := []struct {
+		name string
+		got  uint64
+		want uint64
+	}{
+		{name: "FATAL", got: uint64(FATAL), want: 10},
+		{name: "PANIC", got: uint64(PANIC), want: 254},
+		{name: "first custom level", got: uint64(g.TagStringToLevel("AUDIT")), want: 11},
+		{name: "NONE", got: uint64(NONE), want: 12},
+		{name: "WRITER", got: uint64(WRITER), want: 15},
+		{name: "TraceLineShort", got: uint64(TraceLineShort), want: 1 << 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
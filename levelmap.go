@@ -115,6 +115,35 @@ func (e *entryLevelMap) tryStore(i *LEVEL) bool {
 	}
 }
 
+func (m *levelMap) Delete(key string) {
+	read, _ := m.read.Load().(readOnlyLevelMap)
+	e, ok := read.m[key]
+	if !ok && read.amended {
+		m.mu.Lock()
+		read, _ = m.read.Load().(readOnlyLevelMap)
+		e, ok = read.m[key]
+		if !ok && read.amended {
+			delete(m.dirty, key)
+		}
+		m.mu.Unlock()
+	}
+	if ok {
+		e.delete()
+	}
+}
+
+func (e *entryLevelMap) delete() (hadValue bool) {
+	for {
+		p := atomic.LoadPointer(&e.p)
+		if p == nil || p == expungedLevelMap {
+			return false
+		}
+		if atomic.CompareAndSwapPointer(&e.p, p, nil) {
+			return true
+		}
+	}
+}
+
 func (e *entryLevelMap) unexpungeLocked() (wasExpunged bool) {
 	return atomic.CompareAndSwapPointer(&e.p, expungedLevelMap, nil)
 }
@@ -123,6 +152,31 @@ func (e *entryLevelMap) storeLocked(i *LEVEL) {
 	atomic.StorePointer(&e.p, unsafe.Pointer(i))
 }
 
+func (m *levelMap) Range(f func(key string, value LEVEL) bool) {
+	read, _ := m.read.Load().(readOnlyLevelMap)
+	if read.amended {
+		m.mu.Lock()
+		read, _ = m.read.Load().(readOnlyLevelMap)
+		if read.amended {
+			read = readOnlyLevelMap{m: m.dirty}
+			m.read.Store(read)
+			m.dirty = nil
+			m.misses = 0
+		}
+		m.mu.Unlock()
+	}
+
+	for k, e := range read.m {
+		v, ok := e.load()
+		if !ok {
+			continue
+		}
+		if !f(k, v) {
+			break
+		}
+	}
+}
+
 func (m *levelMap) missLocked() {
 	m.misses++
 	if m.misses < len(m.dirty) {
@@ -0,0 +1,134 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// EnableLogfmt enables logfmt output of all levels including the levels added later
+// by setting LogfmtEncoder as the encoder of all levels, it replaces JSON output.
+// Encoders set by SetLevelEncoder and EnableLevelLogfmt take precedence.
+func (g *Glg) EnableLogfmt() *Glg {
+	return g.SetEncoder(LogfmtEncoder)
+}
+
+// DisableLogfmt disables logfmt output of all levels enabled by EnableLogfmt and turns back to text output
+func (g *Glg) DisableLogfmt() *Glg {
+	if enc := g.encoder.Load(); enc != nil && sameEncoder(*enc, LogfmtEncoder) {
+		g.SetEncoder(nil)
+	}
+	return g
+}
+
+// EnableLevelLogfmt enables logfmt output of the level
+func (g *Glg) EnableLevelLogfmt(lv LEVEL) *Glg {
+	return g.SetLevelEncoder(lv, LogfmtEncoder)
+}
+
+// DisableLevelLogfmt disables logfmt output of the level,
+// the level writes text when logfmt output of all levels is enabled by EnableLogfmt.
+func (g *Glg) DisableLevelLogfmt(lv LEVEL) *Glg {
+	enc := g.encoder.Load()
+	global := enc != nil && sameEncoder(*enc, LogfmtEncoder)
+	g.updateLogger(lv, func(l *logger) {
+		if l.encoder != nil && sameEncoder(l.encoder, LogfmtEncoder) {
+			l.encoder = nil
+		}
+		if l.encoder == nil && global {
+			l.encoder = TextEncoder
+		}
+	})
+	return g
+}
+
+// appendLogfmtKey writes key replacing the characters which are not allowed in logfmt keys by '_'
+func appendLogfmtKey(b *bytes.Buffer, key string) {
+	if key == "" {
+		b.WriteByte('_')
+		return
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+}
+
+// appendLogfmtValue writes value, quoted and escaped when it is empty or contains space, '=', '"' or control characters
+func appendLogfmtValue(b *bytes.Buffer, val string) {
+	if !needsLogfmtQuote(val) {
+		b.WriteString(val)
+		return
+	}
+	b.WriteByte('"')
+	for i := 0; i < len(val); {
+		c := val[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case c == '\n':
+				b.WriteString(`\n`)
+			case c == '\r':
+				b.WriteString(`\r`)
+			case c == '\t':
+				b.WriteString(`\t`)
+			case c < ' ' || c == 0x7f:
+				b.WriteString(`\u00`)
+				b.WriteByte(hexDigits[c>>4])
+				b.WriteByte(hexDigits[c&0xf])
+			default:
+				b.WriteByte(c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(val[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteString(`\ufffd`)
+		} else {
+			b.WriteString(val[i : i+size])
+		}
+		i += size
+	}
+	b.WriteByte('"')
+}
+
+func needsLogfmtQuote(val string) bool {
+	if val == "" {
+		return true
+	}
+	for i := 0; i < len(val); i++ {
+		if c := val[i]; c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			return true
+		}
+	}
+	return !utf8.ValidString(val)
+}
@@ -0,0 +1,166 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_appendLogfmtValue(t *testing.T) {
+	tests := []struct {
+		name string
+		val  string
+		want string
+	}{
+		{name: "bare value", val: "value", want: "value"},
+		{name: "empty value", val: "", want: `""`},
+		{name: "space", val: "hello world", want: `"hello world"`},
+		{name: "equal sign", val: "a=b", want: `"a=b"`},
+		{name: "quote and backslash", val: `say "hi" \o/`, want: `"say \"hi\" \\o/"`},
+		{name: "bare backslash", val: `C:\tmp`, want: `C:\tmp`},
+		{name: "control characters", val: "a\nb\tc\x01", want: `"a\nb\tc\u0001"`},
+		{name: "unicode", val: "日本語", want: "日本語"},
+		{name: "invalid utf8", val: "a\xffb", want: `"a\ufffdb"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			appendLogfmtValue(b, tt.val)
+			if got := b.String(); got != tt.want {
+				t.Errorf("appendLogfmtValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_appendLogfmtKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "user_id", want: "user_id"},
+		{key: "", want: "_"},
+		{key: "a b=c\"d", want: "a_b_c_d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			appendLogfmtKey(b, tt.key)
+			if got := b.String(); got != tt.want {
+				t.Errorf("appendLogfmtKey() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_EnableLogfmt(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(g *Glg) *Glg
+		log   func(g *Glg) error
+		want  string
+	}{
+		{
+			name:  "global logfmt with fields",
+			setup: func(g *Glg) *Glg { return g.EnableLogfmt() },
+			log: func(g *Glg) error {
+				return g.With("user", "john doe", "err", errors.New("boom")).Info("hello", "world")
+			},
+			want: `^level=INFO msg="hello world" user="john doe" err=boom\n$`,
+		},
+		{
+			name:  "formatted message",
+			setup: func(g *Glg) *Glg { return g.EnableLogfmt() },
+			log:   func(g *Glg) error { return g.Warnf("%d%% done", 50) },
+			want:  `^level=WARN msg="50% done"\n$`,
+		},
+		{
+			name: "timestamp and caller",
+			setup: func(g *Glg) *Glg {
+				return g.EnableTimestamp().SetTimeFormat(time.RFC3339Nano).SetLevelLineTraceMode(ERR, TraceLineShort).EnableLogfmt()
+			},
+			log:  func(g *Glg) error { return g.Error("failed") },
+			want: `^ts=\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(\.\d+)?(Z|[+-]\d\d:\d\d) level=ERR caller=logfmt_test\.go:\d+ msg=failed\n$`,
+		},
+		{
+			name:  "level logfmt takes precedence over json",
+			setup: func(g *Glg) *Glg { return g.EnableJSON().EnableLevelLogfmt(INFO) },
+			log:   func(g *Glg) error { return g.Info("msg") },
+			want:  `^level=INFO msg=msg\n$`,
+		},
+		{
+			name:  "other levels keep text format",
+			setup: func(g *Glg) *Glg { return g.EnableLevelLogfmt(WARN) },
+			log:   func(g *Glg) error { return g.Info("msg") },
+			want:  `^\[INFO\]:\tmsg\n$`,
+		},
+		{
+			name:  "disabled global logfmt",
+			setup: func(g *Glg) *Glg { return g.EnableLogfmt().DisableLogfmt() },
+			log:   func(g *Glg) error { return g.Info("msg") },
+			want:  `^\[INFO\]:\tmsg\n$`,
+		},
+		{
+			name:  "json replaces global logfmt",
+			setup: func(g *Glg) *Glg { return g.EnableLogfmt().EnableJSON() },
+			log:   func(g *Glg) error { return g.Info("msg") },
+			want:  `^\{"level":"INFO","detail":"msg"\}\n$`,
+		},
+		{
+			name:  "disabled level logfmt",
+			setup: func(g *Glg) *Glg { return g.EnableLogfmt().DisableLevelLogfmt(INFO) },
+			log:   func(g *Glg) error { return g.Info("msg") },
+			want:  `^\[INFO\]:\tmsg\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineNone)
+			g = tt.setup(g)
+			if err := tt.log(g); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); !regexp.MustCompile(tt.want).MatchString(got) {
+				t.Errorf("output = %q, want match %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_EnableLogfmtAddedLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().EnableLogfmt().AddStdLevel("NOTICE", WRITER, false).
+		SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineNone)
+	if err := g.CustomLog("NOTICE", "n"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "level=NOTICE msg=n\n"; got != want {
+		t.Errorf("output of level added after EnableLogfmt = %q, want %q", got, want)
+	}
+}
@@ -115,6 +115,35 @@ func (e *entryLoggers) tryStore(i **logger) bool {
 	}
 }
 
+func (m *loggers) Delete(key LEVEL) {
+	read, _ := m.read.Load().(readOnlyLoggers)
+	e, ok := read.m[key]
+	if !ok && read.amended {
+		m.mu.Lock()
+		read, _ = m.read.Load().(readOnlyLoggers)
+		e, ok = read.m[key]
+		if !ok && read.amended {
+			delete(m.dirty, key)
+		}
+		m.mu.Unlock()
+	}
+	if ok {
+		e.delete()
+	}
+}
+
+func (e *entryLoggers) delete() (hadValue bool) {
+	for {
+		p := atomic.LoadPointer(&e.p)
+		if p == nil || p == expungedLoggers {
+			return false
+		}
+		if atomic.CompareAndSwapPointer(&e.p, p, nil) {
+			return true
+		}
+	}
+}
+
 func (e *entryLoggers) unexpungeLocked() (wasExpunged bool) {
 	return atomic.CompareAndSwapPointer(&e.p, expungedLoggers, nil)
 }
@@ -0,0 +1,161 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// levelStats is the counters of a level shared by the copies of its logger
+type levelStats struct {
+	emitted    uint64
+	suppressed uint64
+	failed     uint64
+}
+
+// LevelStats is the counters of a level
+type LevelStats struct {
+	Level LEVEL  `json:"-"`
+	Tag   string `json:"-"`
+	// Emitted is the number of entries written, or queued when async logging is enabled
+	Emitted uint64 `json:"emitted"`
+	// Suppressed is the number of entries discarded because the mode of the level is NONE
+	Suppressed uint64 `json:"suppressed"`
+	// Failed is the number of failed writes, async write failures are counted after the entries are counted as emitted
+	Failed uint64 `json:"failed"`
+}
+
+func (s *levelStats) emit() {
+	if s != nil {
+		atomic.AddUint64(&s.emitted, 1)
+	}
+}
+
+func (s *levelStats) suppress() {
+	if s != nil {
+		atomic.AddUint64(&s.suppressed, 1)
+	}
+}
+
+func (s *levelStats) fail() {
+	if s != nil {
+		atomic.AddUint64(&s.failed, 1)
+	}
+}
+
+// Stats returns the counters of all levels ordered by severity
+func (g *Glg) Stats() []LevelStats {
+	var (
+		stats []LevelStats
+		sevs  = make(map[LEVEL]Severity)
+	)
+	g.logger.Range(func(lev LEVEL, l *logger) bool {
+		s := LevelStats{
+			Level: lev,
+			Tag:   l.tag,
+		}
+		if l.stats != nil {
+			s.Emitted = atomic.LoadUint64(&l.stats.emitted)
+			s.Suppressed = atomic.LoadUint64(&l.stats.suppressed)
+			s.Failed = atomic.LoadUint64(&l.stats.failed)
+		}
+		stats = append(stats, s)
+		sevs[lev] = l.severity
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		si, sj := sevs[stats[i].Level], sevs[stats[j].Level]
+		if si != sj {
+			return si < sj
+		}
+		return stats[i].Level < stats[j].Level
+	})
+	return stats
+}
+
+// Stats returns the counters of all levels ordered by severity
+func Stats() []LevelStats {
+	return glg.Stats()
+}
+
+// MetricsHandler returns http.Handler which exposes the level counters and the number of async dropped entries
+// in Prometheus text format, such as glg_entries_emitted_total{level="ERR"} 3.
+func (g *Glg) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := new(bytes.Buffer)
+		stats := g.Stats()
+		for _, m := range []struct {
+			name, help string
+			value      func(LevelStats) uint64
+		}{
+			{"glg_entries_emitted_total", "Number of log entries written or queued.", func(s LevelStats) uint64 { return s.Emitted }},
+			{"glg_entries_suppressed_total", "Number of log entries discarded by mode NONE.", func(s LevelStats) uint64 { return s.Suppressed }},
+			{"glg_write_failures_total", "Number of failed log writes.", func(s LevelStats) uint64 { return s.Failed }},
+		} {
+			fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", m.name, m.help, m.name)
+			for _, s := range stats {
+				fmt.Fprintf(b, "%s{level=\"%s\"} %d\n", m.name, escapeLabel(s.Tag), m.value(s))
+			}
+		}
+		b.WriteString("# HELP glg_async_dropped_total Number of log entries dropped by the async overflow policy.\n")
+		b.WriteString("# TYPE glg_async_dropped_total counter\n")
+		b.WriteString("glg_async_dropped_total " + strconv.FormatUint(g.AsyncDropped(), 10) + "\n")
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(b.Bytes())
+	})
+}
+
+// MetricsHandler returns http.Handler which exposes the level counters in Prometheus text format
+func MetricsHandler() http.Handler {
+	return glg.MetricsHandler()
+}
+
+// PublishExpvar publishes the level counters as expvar variable name, a map from tag to the counters.
+// The name already published is reported to the error handler.
+func (g *Glg) PublishExpvar(name string) *Glg {
+	if expvar.Get(name) != nil {
+		g.handleError(fmt.Errorf("expvar %s is already published", name))
+		return g
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		m := make(map[string]LevelStats)
+		for _, s := range g.Stats() {
+			m[s.Tag] = s
+		}
+		return m
+	}))
+	return g
+}
+
+// escapeLabel escapes Prometheus label value
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
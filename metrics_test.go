@@ -0,0 +1,188 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// failWriter fails every write
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func newStatsLogger() *Glg {
+	return New().
+		AddStdLevelAt("NOTICE", WRITER, false, SeverityOf(WARN)-1).
+		SetMode(WRITER).
+		SetWriter(new(bytes.Buffer)).
+		SetLevelWriter(FAIL, failWriter{}).
+		SetLevel(INFO)
+}
+
+func statsOf(stats []LevelStats, tag string) LevelStats {
+	for _, s := range stats {
+		if s.Tag == tag {
+			return s
+		}
+	}
+	return LevelStats{}
+}
+
+func TestGlg_Stats(t *testing.T) {
+	tests := []struct {
+		name  string
+		async bool
+	}{
+		{
+			name:  "sync",
+			async: false,
+		},
+		{
+			name:  "async",
+			async: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newStatsLogger().SetErrorHandler(func(error) {})
+			if tt.async {
+				g.EnableAsync(10, OverflowBlock)
+			}
+			g.Debug("suppressed")
+			g.Debug("suppressed")
+			g.Info("emitted")
+			g.Error("emitted")
+			g.Error("emitted")
+			g.CustomLog("notice", "emitted")
+			g.Fail("failed")
+			g.Close()
+			stats := g.Stats()
+			want := map[string]LevelStats{
+				"DEBG":   {Level: DEBG, Tag: "DEBG", Suppressed: 2},
+				"INFO":   {Level: INFO, Tag: "INFO", Emitted: 1},
+				"ERR":    {Level: ERR, Tag: "ERR", Emitted: 2},
+				"NOTICE": {Level: g.TagStringToLevel("NOTICE"), Tag: "NOTICE", Emitted: 1},
+				"FAIL":   {Level: FAIL, Tag: "FAIL", Failed: 1},
+			}
+			if tt.async {
+				want["FAIL"] = LevelStats{Level: FAIL, Tag: "FAIL", Emitted: 1, Failed: 1}
+			}
+			for tag, w := range want {
+				if got := statsOf(stats, tag); !reflect.DeepEqual(got, w) {
+					t.Errorf("Stats() of %s = %+v, want %+v", tag, got, w)
+				}
+			}
+			var tags []string
+			for _, s := range stats {
+				tags = append(tags, s.Tag)
+			}
+			if got, want := strings.Join(tags, ","), "DEBG,TRACE,PRINT,LOG,INFO,OK,NOTICE,WARN,ERR,FAIL,PANIC,FATAL"; got != want {
+				t.Errorf("Stats() order = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestGlg_StatsReconfigure(t *testing.T) {
+	g := newStatsLogger()
+	g.Info("before")
+	g.EnableColor().SetLevelMode(INFO, NONE)
+	g.Info("after")
+	if got := statsOf(g.Stats(), "INFO"); got.Emitted != 1 || got.Suppressed != 1 {
+		t.Errorf("Stats() of INFO = %+v, want 1 emitted and 1 suppressed", got)
+	}
+}
+
+func TestGlg_MetricsHandler(t *testing.T) {
+	g := newStatsLogger().SetErrorHandler(func(error) {}).SetPrefix(PRINT, `a"b`)
+	g.Error("emitted")
+	g.Debug("suppressed")
+	g.Fail("failed")
+	rec := httptest.NewRecorder()
+	g.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %s", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE glg_entries_emitted_total counter\n",
+		`glg_entries_emitted_total{level="ERR"} 1` + "\n",
+		`glg_entries_suppressed_total{level="DEBG"} 1` + "\n",
+		`glg_write_failures_total{level="FAIL"} 1` + "\n",
+		`glg_entries_emitted_total{level="a\"b"} 0` + "\n",
+		"glg_async_dropped_total 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestGlg_MetricsHandlerAsyncDropped(t *testing.T) {
+	w := newBlockWriter()
+	g := New().SetMode(WRITER).SetWriter(w).EnableAsync(1, OverflowDropNewest)
+	g.Info("written")
+	<-w.started
+	g.Info("queued")
+	g.Info("dropped")
+	close(w.release)
+	g.DisableAsync().EnableAsync(1, OverflowDropNewest)
+	defer g.Close()
+	rec := httptest.NewRecorder()
+	g.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "glg_async_dropped_total 1\n") {
+		t.Errorf("metrics do not keep the dropped entries after EnableAsync:\n%s", body)
+	}
+}
+
+func TestGlg_PublishExpvar(t *testing.T) {
+	var handled error
+	g := newStatsLogger().SetErrorHandler(func(err error) { handled = err })
+	g.PublishExpvar("glg_test_stats")
+	g.Warn("emitted")
+	v := expvar.Get("glg_test_stats")
+	if v == nil {
+		t.Fatal("expvar is not published")
+	}
+	var got map[string]map[string]uint64
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]uint64{"emitted": 1, "suppressed": 0, "failed": 0}; !reflect.DeepEqual(got["WARN"], want) {
+		t.Errorf("expvar WARN = %v, want %v", got["WARN"], want)
+	}
+	if g.PublishExpvar("glg_test_stats"); handled == nil {
+		t.Error("PublishExpvar() of published name did not report error")
+	}
+}
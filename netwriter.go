@@ -0,0 +1,479 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Framing is how NetworkWriter delimits entries on the connection
+type Framing uint8
+
+const (
+	// FramingNewline terminates each entry by newline unless it already ends with newline
+	FramingNewline Framing = iota + 1
+	// FramingLengthPrefix prefixes each entry by its length as 4 bytes big endian integer
+	FramingLengthPrefix
+	// FramingNone writes entries as they are, it is for datagram networks such as udp
+	FramingNone
+
+	// DefaultNetworkBufferSize is the default size in bytes of in-memory buffer of NetworkWriter
+	DefaultNetworkBufferSize = 1 << 20
+
+	defaultWriteTimeout = 5 * time.Second
+	defaultMinBackoff   = 100 * time.Millisecond
+	defaultMaxBackoff   = 30 * time.Second
+
+	spillHeaderSize = 4
+)
+
+// ErrNetworkWriterClosed is returned by writing to closed NetworkWriter
+var ErrNetworkWriterClosed = errors.New("network writer is closed")
+
+// NetworkStats is statistics of NetworkWriter, the sizes are in bytes of framed entries
+type NetworkStats struct {
+	// Written is the size written to the connection
+	Written uint64
+	// Dropped is the size dropped because the buffer was full or the writer was closed
+	Dropped uint64
+	// Retried is the size written again after a connection failure
+	Retried uint64
+	// Spilled is the size written to the spill file
+	Spilled uint64
+	// Reconnects is the number of established connections after the first one
+	Reconnects uint64
+	// Buffered is the size waiting to be written
+	Buffered int64
+	// LastError is the last connection or write error
+	LastError error
+}
+
+// NetworkWriter is io.WriteCloser which delivers entries to a network destination.
+// Write only buffers the entry and a background goroutine writes it to the connection,
+// so logging is not blocked while the destination is unavailable.
+// The connection is reestablished with exponential backoff, meanwhile entries are kept in memory
+// and spilled to a file when the memory buffer is full and EnableSpill is set, otherwise they are dropped.
+type NetworkWriter struct {
+	network      string
+	addr         string
+	tlsConfig    *tls.Config
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	framing      Framing
+	bufferSize   int64
+	spillPath    string
+	maxSpill     int64
+
+	once    sync.Once
+	mu      sync.Mutex
+	wake    chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+	closed  bool
+	queue   [][]byte
+	queued  int64
+	spill   *os.File
+	spillR  int64
+	spillW  int64
+	conn    net.Conn
+	lastErr error
+
+	written    uint64
+	dropped    uint64
+	retried    uint64
+	spilled    uint64
+	reconnects uint64
+}
+
+var _ io.WriteCloser = (*NetworkWriter)(nil)
+
+// NewNetworkWriter returns writer which delivers entries to addr on network such as "tcp", "udp" or "unix"
+func NewNetworkWriter(network, addr string) *NetworkWriter {
+	return &NetworkWriter{
+		network:      network,
+		addr:         addr,
+		dialTimeout:  defaultDialTimeout,
+		writeTimeout: defaultWriteTimeout,
+		minBackoff:   defaultMinBackoff,
+		maxBackoff:   defaultMaxBackoff,
+		framing:      FramingNewline,
+		bufferSize:   DefaultNetworkBufferSize,
+		wake:         make(chan struct{}, 1),
+		closing:      make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// SetDialTimeout sets connection timeout
+func (n *NetworkWriter) SetDialTimeout(timeout time.Duration) *NetworkWriter {
+	n.dialTimeout = timeout
+	return n
+}
+
+// SetWriteTimeout sets write timeout of an entry, non positive timeout disables it
+func (n *NetworkWriter) SetWriteTimeout(timeout time.Duration) *NetworkWriter {
+	n.writeTimeout = timeout
+	return n
+}
+
+// SetBackoff sets the first and the maximum reconnect interval after a dial or write failure,
+// the interval doubles on each failure until an entry is written
+func (n *NetworkWriter) SetBackoff(min, max time.Duration) *NetworkWriter {
+	if min > 0 {
+		n.minBackoff = min
+	}
+	if max >= n.minBackoff {
+		n.maxBackoff = max
+	}
+	return n
+}
+
+// SetFraming sets framing of entries, FramingNewline is default
+func (n *NetworkWriter) SetFraming(framing Framing) *NetworkWriter {
+	n.framing = framing
+	return n
+}
+
+// SetBufferSize sets the size in bytes of in-memory buffer
+func (n *NetworkWriter) SetBufferSize(size int64) *NetworkWriter {
+	n.bufferSize = size
+	return n
+}
+
+// SetTLSConfig enables TLS with cfg
+func (n *NetworkWriter) SetTLSConfig(cfg *tls.Config) *NetworkWriter {
+	n.tlsConfig = cfg
+	return n
+}
+
+// EnableSpill writes entries to the file at path when in-memory buffer is full, up to maxSize bytes.
+// Non positive maxSize is unlimited. The file is truncated on use and removed by Close.
+func (n *NetworkWriter) EnableSpill(path string, maxSize int64) *NetworkWriter {
+	n.spillPath = path
+	n.maxSpill = maxSize
+	return n
+}
+
+// Write buffers the entry to be delivered, it does not wait for the delivery
+func (n *NetworkWriter) Write(b []byte) (int, error) {
+	n.once.Do(func() {
+		go n.run()
+	})
+	frame := n.frame(b)
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return 0, ErrNetworkWriterClosed
+	}
+	// entries go to the spill file while it has entries to keep the order
+	if n.queued+int64(len(frame)) <= n.bufferSize && n.spillW == n.spillR {
+		n.queue = append(n.queue, frame)
+		n.queued += int64(len(frame))
+	} else if err := n.spillFrame(frame); err != nil {
+		atomic.AddUint64(&n.dropped, uint64(len(frame)))
+		n.lastErr = err
+	}
+	n.mu.Unlock()
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+	return len(b), nil
+}
+
+func (n *NetworkWriter) frame(b []byte) []byte {
+	switch n.framing {
+	case FramingLengthPrefix:
+		frame := make([]byte, 4, len(b)+4)
+		binary.BigEndian.PutUint32(frame, uint32(len(b)))
+		return append(frame, b...)
+	case FramingNewline:
+		if len(b) == 0 || b[len(b)-1] != '\n' {
+			return append(append(make([]byte, 0, len(b)+1), b...), '\n')
+		}
+	}
+	return append([]byte(nil), b...)
+}
+
+// spillFrame appends the frame to the spill file as length and frame
+func (n *NetworkWriter) spillFrame(frame []byte) (err error) {
+	if n.spillPath == "" {
+		return errors.New("network buffer is full")
+	}
+	size := int64(spillHeaderSize + len(frame))
+	if n.maxSpill > 0 && n.spillW-n.spillR+size > n.maxSpill {
+		return errors.New("network spill file is full")
+	}
+	if n.spill == nil {
+		n.spill, err = os.OpenFile(n.spillPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			n.spill = nil
+			return err
+		}
+	}
+	b := make([]byte, size)
+	binary.BigEndian.PutUint32(b, uint32(len(frame)))
+	copy(b[spillHeaderSize:], frame)
+	_, err = n.spill.WriteAt(b, n.spillW)
+	if err != nil {
+		return err
+	}
+	n.spillW += size
+	atomic.AddUint64(&n.spilled, uint64(len(frame)))
+	return nil
+}
+
+// next returns the oldest buffered frame without removing it, it waits for a frame until closing
+func (n *NetworkWriter) next() (frame []byte, spilled bool, ok bool) {
+	for {
+		n.mu.Lock()
+		if len(n.queue) != 0 {
+			frame = n.queue[0]
+			n.mu.Unlock()
+			return frame, false, true
+		}
+		if n.spillR < n.spillW {
+			frame, err := n.readSpill()
+			if err != nil {
+				// the rest of the spill file cannot be read
+				atomic.AddUint64(&n.dropped, uint64(n.spillW-n.spillR))
+				n.lastErr = err
+				n.spillR, n.spillW = 0, 0
+				n.mu.Unlock()
+				continue
+			}
+			n.mu.Unlock()
+			return frame, true, true
+		}
+		closed := n.closed
+		n.mu.Unlock()
+		if closed {
+			return nil, false, false
+		}
+		select {
+		case <-n.wake:
+		case <-n.closing:
+		}
+	}
+}
+
+func (n *NetworkWriter) readSpill() ([]byte, error) {
+	var h [spillHeaderSize]byte
+	_, err := n.spill.ReadAt(h[:], n.spillR)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, binary.BigEndian.Uint32(h[:]))
+	_, err = n.spill.ReadAt(frame, n.spillR+spillHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// commit removes the frame returned by next
+func (n *NetworkWriter) commit(frame []byte, spilled bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !spilled {
+		n.queue[0] = nil
+		n.queue = n.queue[1:]
+		n.queued -= int64(len(frame))
+		return
+	}
+	n.spillR += int64(spillHeaderSize + len(frame))
+	if n.spillR == n.spillW {
+		n.spillR, n.spillW = 0, 0
+		n.spill.Truncate(0)
+	}
+}
+
+func (n *NetworkWriter) run() {
+	defer close(n.done)
+	backoff := n.minBackoff
+	connected := false
+	for {
+		frame, spilled, ok := n.next()
+		if !ok {
+			break
+		}
+		retry := false
+		for {
+			if n.conn == nil {
+				conn, err := n.dial()
+				if err != nil {
+					n.setError(err)
+					if !n.sleep(backoff) {
+						// closed while disconnected
+						n.dropAll()
+						return
+					}
+					backoff = min(backoff*2, n.maxBackoff)
+					continue
+				}
+				n.conn = conn
+				if connected {
+					atomic.AddUint64(&n.reconnects, 1)
+				}
+				connected = true
+			}
+			if retry {
+				atomic.AddUint64(&n.retried, uint64(len(frame)))
+			}
+			if n.writeTimeout > 0 {
+				n.conn.SetWriteDeadline(time.Now().Add(n.writeTimeout))
+			}
+			_, err := n.conn.Write(frame)
+			if err == nil {
+				atomic.AddUint64(&n.written, uint64(len(frame)))
+				n.commit(frame, spilled)
+				backoff = n.minBackoff
+				break
+			}
+			n.setError(err)
+			n.conn.Close()
+			n.conn = nil
+			retry = true
+			// the destination may accept connections and fail every write,
+			// so the backoff is reset only by a successful write
+			if !n.sleep(backoff) {
+				// closed while the writes fail
+				n.dropAll()
+				return
+			}
+			backoff = min(backoff*2, n.maxBackoff)
+		}
+	}
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn = nil
+	}
+}
+
+func (n *NetworkWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: n.dialTimeout}
+	if n.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, n.network, n.addr, n.tlsConfig)
+	}
+	return dialer.Dial(n.network, n.addr)
+}
+
+// sleep waits for d and reports false when the writer is closed
+func (n *NetworkWriter) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-n.closing:
+		return false
+	}
+}
+
+func (n *NetworkWriter) setError(err error) {
+	n.mu.Lock()
+	n.lastErr = err
+	n.mu.Unlock()
+}
+
+// dropAll drops all buffered entries
+func (n *NetworkWriter) dropAll() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	atomic.AddUint64(&n.dropped, uint64(n.queued+n.spillW-n.spillR))
+	n.queue, n.queued = nil, 0
+	n.spillR, n.spillW = 0, 0
+}
+
+// Flush waits until all buffered entries are written or ctx is done
+func (n *NetworkWriter) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for n.Stats().Buffered > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-n.done:
+			return nil
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Stats returns statistics of the writer
+func (n *NetworkWriter) Stats() NetworkStats {
+	n.mu.Lock()
+	buffered := n.queued + n.spillW - n.spillR
+	lastErr := n.lastErr
+	n.mu.Unlock()
+	return NetworkStats{
+		Written:    atomic.LoadUint64(&n.written),
+		Dropped:    atomic.LoadUint64(&n.dropped),
+		Retried:    atomic.LoadUint64(&n.retried),
+		Spilled:    atomic.LoadUint64(&n.spilled),
+		Reconnects: atomic.LoadUint64(&n.reconnects),
+		Buffered:   buffered,
+		LastError:  lastErr,
+	}
+}
+
+// Close writes buffered entries while the connection is available and closes it.
+// The entries which cannot be written are dropped and the spill file is removed.
+func (n *NetworkWriter) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+	n.mu.Unlock()
+	close(n.closing)
+	started := true
+	n.once.Do(func() {
+		started = false
+	})
+	if started {
+		<-n.done
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.spill == nil {
+		return nil
+	}
+	err := n.spill.Close()
+	n.spill = nil
+	return errors.Join(err, os.Remove(n.spillPath))
+}
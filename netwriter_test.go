@@ -0,0 +1,362 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lineServer accepts connections and sends received newline framed entries to the channel.
+// The connections are closed with the listener by calling stop.
+func lineServer(t *testing.T, ln net.Listener) (lines <-chan string, stop func()) {
+	t.Helper()
+	ch := make(chan string, 1024)
+	var (
+		mu    sync.Mutex
+		conns []net.Conn
+	)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					ch <- line
+				}
+			}()
+		}
+	}()
+	stop = func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+	t.Cleanup(stop)
+	return ch, stop
+}
+
+func receive(t *testing.T, ch <-chan string, n int) []string {
+	t.Helper()
+	got := make([]string, 0, n)
+	for len(got) < n {
+		select {
+		case s := <-ch:
+			got = append(got, s)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %v, want %d entries", got, n)
+		}
+	}
+	return got
+}
+
+func flush(t *testing.T, n *NetworkWriter) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := n.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v, stats = %+v", err, n.Stats())
+	}
+}
+
+// unusedAddr returns local tcp address which nobody listens on
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestNetworkWriter_Newline(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines, _ := lineServer(t, ln)
+
+	n := NewNetworkWriter("tcp", ln.Addr().String())
+	defer n.Close()
+	g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).SetLevelWriter(INFO, n)
+	g.Info("first")
+	g.Info("second")
+	n.Write([]byte("raw"))
+	flush(t, n)
+
+	want := []string{"[INFO]:\tfirst\n", "[INFO]:\tsecond\n", "raw\n"}
+	if got := receive(t, lines, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("received %q, want %q", got, want)
+	}
+	if st := n.Stats(); st.Written != 33 || st.Dropped != 0 || st.Buffered != 0 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
+
+func TestNetworkWriter_LengthPrefix(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	frames := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var h [4]byte
+			if _, err := io.ReadFull(conn, h[:]); err != nil {
+				return
+			}
+			b := make([]byte, binary.BigEndian.Uint32(h[:]))
+			if _, err := io.ReadFull(conn, b); err != nil {
+				return
+			}
+			frames <- string(b)
+		}
+	}()
+
+	n := NewNetworkWriter("tcp", ln.Addr().String()).SetFraming(FramingLengthPrefix)
+	defer n.Close()
+	n.Write([]byte("a\nb"))
+	n.Write([]byte("c"))
+	if got, want := receive(t, frames, 2), []string{"a\nb", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
+
+func TestNetworkWriter_Reconnect(t *testing.T) {
+	addr := unusedAddr(t)
+	n := NewNetworkWriter("tcp", addr).SetBackoff(5*time.Millisecond, 20*time.Millisecond)
+	defer n.Close()
+
+	// entries are buffered while the destination is down
+	for i := 0; i < 3; i++ {
+		n.Write([]byte("before " + strconv.Itoa(i)))
+	}
+	time.Sleep(30 * time.Millisecond)
+	if st := n.Stats(); st.Buffered == 0 || st.LastError == nil {
+		t.Errorf("Stats() while disconnected = %+v", st)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skip(err)
+	}
+	lines, stop := lineServer(t, ln)
+	flush(t, n)
+	want := []string{"before 0\n", "before 1\n", "before 2\n"}
+	if got := receive(t, lines, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("received %q, want %q", got, want)
+	}
+
+	// restart the destination
+	stop()
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skip(err)
+	}
+	lines, _ = lineServer(t, ln)
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; n.Stats().Reconnects == 0; i++ {
+		if time.Now().After(deadline) {
+			t.Fatalf("not reconnected: %+v", n.Stats())
+		}
+		n.Write([]byte("after " + strconv.Itoa(i)))
+		time.Sleep(5 * time.Millisecond)
+	}
+	flush(t, n)
+	if got := receive(t, lines, 1); len(got[0]) == 0 {
+		t.Error("nothing is received after reconnect")
+	}
+}
+
+func TestNetworkWriter_WriteErrorBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu    sync.Mutex
+		conns []net.Conn
+	)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	defer func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	// every write fails by the write timeout while the dials succeed
+	n := NewNetworkWriter("tcp", ln.Addr().String()).SetWriteTimeout(time.Nanosecond).SetBackoff(50*time.Millisecond, time.Second)
+	n.Write([]byte("fail"))
+	time.Sleep(200 * time.Millisecond)
+	n.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conns) > 5 {
+		t.Errorf("dialed %d times in 200ms with 50ms backoff, stats = %+v", len(conns), n.Stats())
+	}
+}
+
+func TestNetworkWriter_Buffer(t *testing.T) {
+	tests := []struct {
+		name        string
+		spill       bool
+		maxSpill    int64
+		wantDropped uint64
+		wantSpilled uint64
+		wantLines   int
+	}{
+		{name: "drop when buffer is full", wantDropped: 16, wantLines: 3},
+		{name: "spill to file", spill: true, wantSpilled: 16, wantLines: 5},
+		{name: "drop when spill file is full", spill: true, maxSpill: 12, wantDropped: 8, wantSpilled: 8, wantLines: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := unusedAddr(t)
+			spillPath := filepath.Join(t.TempDir(), "spill")
+			n := NewNetworkWriter("tcp", addr).SetBackoff(5*time.Millisecond, 10*time.Millisecond).SetBufferSize(24)
+			if tt.spill {
+				n.EnableSpill(spillPath, tt.maxSpill)
+			}
+			for i := 0; i < 5; i++ {
+				n.Write([]byte("entry " + strconv.Itoa(i)))
+			}
+			st := n.Stats()
+			if st.Dropped != tt.wantDropped || st.Spilled != tt.wantSpilled {
+				t.Errorf("Stats() = %+v, want dropped %d spilled %d", st, tt.wantDropped, tt.wantSpilled)
+			}
+
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				t.Skip(err)
+			}
+			defer ln.Close()
+			lines, _ := lineServer(t, ln)
+			flush(t, n)
+			got := receive(t, lines, tt.wantLines)
+			for i, line := range got {
+				if want := "entry " + strconv.Itoa(i) + "\n"; line != want {
+					t.Errorf("line %d = %q, want %q", i, line, want)
+				}
+			}
+			if err := n.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(spillPath); !os.IsNotExist(err) {
+				t.Errorf("spill file is not removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestNetworkWriter_TLS(t *testing.T) {
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	defer ts.Close()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", ts.TLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines, _ := lineServer(t, ln)
+
+	cfg := ts.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	cfg.ServerName = "example.com"
+	n := NewNetworkWriter("tcp", ln.Addr().String()).SetTLSConfig(cfg)
+	defer n.Close()
+	n.Write([]byte("secure"))
+	if got := receive(t, lines, 1); got[0] != "secure\n" {
+		t.Errorf("received %q", got)
+	}
+}
+
+func TestNetworkWriter_Close(t *testing.T) {
+	n := NewNetworkWriter("tcp", unusedAddr(t)).SetBackoff(time.Hour, time.Hour)
+	n.Write([]byte("lost"))
+	done := make(chan error)
+	go func() { done <- n.Close() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() blocks while disconnected")
+	}
+	if st := n.Stats(); st.Dropped != 5 {
+		t.Errorf("Stats() = %+v, want 5 dropped bytes", st)
+	}
+	if _, err := n.Write([]byte("x")); !errors.Is(err, ErrNetworkWriterClosed) {
+		t.Errorf("Write() after Close error = %v", err)
+	}
+}
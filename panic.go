@@ -0,0 +1,145 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Panic outputs Panic log and panics with the message
+func (g *Glg) Panic(val ...interface{}) {
+	g.panicOutput(g.fields, g.blankFormat(len(val)), val...)
+}
+
+// Panicf outputs formatted Panic log and panics with the message
+func (g *Glg) Panicf(format string, val ...interface{}) {
+	g.panicOutput(g.fields, format, val...)
+}
+
+// PanicFunc outputs Panic log returned from the function and panics with it,
+// the function is called even if PANIC level is disabled.
+func (g *Glg) PanicFunc(f func() string) {
+	g.panicOutput(g.fields, "%s", f())
+}
+
+// Panic outputs Panic log and panics with the message
+func Panic(val ...interface{}) {
+	glg.panicOutput(glg.fields, glg.blankFormat(len(val)), val...)
+}
+
+// Panicf outputs formatted Panic log and panics with the message
+func Panicf(format string, val ...interface{}) {
+	glg.panicOutput(glg.fields, format, val...)
+}
+
+// PanicFunc outputs Panic log returned from the function and panics with it
+func PanicFunc(f func() string) {
+	glg.panicOutput(glg.fields, "%s", f())
+}
+
+// Recover recovers the panic of the goroutine and logs its value with the goroutine stack at lv.
+// It must be called directly by defer, e.g. defer glg.Recover(glg.ERR)
+func (g *Glg) Recover(lv LEVEL) {
+	if r := recover(); r != nil {
+		g.logPanic(lv, r, nil)
+	}
+}
+
+// Recover recovers the panic of the goroutine and logs its value with the goroutine stack at lv
+func Recover(lv LEVEL) {
+	if r := recover(); r != nil {
+		glg.logPanic(lv, r, nil)
+	}
+}
+
+// RecoverHandler returns http.Handler which recovers the panics of handler,
+// logs them with the goroutine stack at lv and responds 500 Internal Server Error.
+// http.ErrAbortHandler is panicked again so that net/http aborts the response.
+func (g *Glg) RecoverHandler(lv LEVEL, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			g.logPanic(lv, rec, []Field{
+				{Key: "method", Value: r.Method},
+				{Key: "uri", Value: r.RequestURI},
+			})
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// RecoverHandler returns http.Handler which recovers the panics of handler and responds 500 Internal Server Error
+func RecoverHandler(lv LEVEL, handler http.Handler) http.Handler {
+	return glg.RecoverHandler(lv, handler)
+}
+
+// panicOutput outputs Panic log with fields and panics with the message of the entry, it is called by the Panic functions
+func (g *Glg) panicOutput(fields []Field, format string, val ...interface{}) {
+	err := g.output(PANIC, g.callerDepth()+1, 0, fields, format, val...)
+	if err != nil {
+		err = g.out(ERR, g.blankFormat(1), err.Error())
+		if err != nil {
+			panic(err)
+		}
+	}
+	panic(formatMessage(format, val...))
+}
+
+// logPanic logs the recovered panic value r with the goroutine stack,
+// the line trace is the place where the panic occurred.
+func (g *Glg) logPanic(lv LEVEL, r interface{}, fields []Field) {
+	if len(g.fields) != 0 {
+		fields = append(g.fields[:len(g.fields):len(g.fields)], fields...)
+	}
+	g.handleError(g.output(lv, -1, panicCaller(), fields, "panic: %v\n%s", r, debug.Stack()))
+}
+
+// panicCaller returns pc of the function which panicked, or zero when the goroutine is not panicking
+func panicCaller() uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		} else if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame.PC + 1
+		}
+		if !more {
+			return 0
+		}
+	}
+}
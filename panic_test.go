@@ -0,0 +1,251 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// catchPanic returns the value f panicked with
+func catchPanic(f func()) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	f()
+	return nil
+}
+
+func TestGlg_Panic(t *testing.T) {
+	tests := []struct {
+		name string
+		mode MODE
+		f    func(g *Glg)
+		want string
+	}{
+		{
+			name: "Panic",
+			mode: WRITER,
+			f: func(g *Glg) {
+				g.Panic("broken", 1)
+			},
+			want: "broken 1",
+		},
+		{
+			name: "Panicf",
+			mode: WRITER,
+			f: func(g *Glg) {
+				g.Panicf("broken %d", 2)
+			},
+			want: "broken 2",
+		},
+		{
+			name: "PanicFunc",
+			mode: WRITER,
+			f: func(g *Glg) {
+				g.PanicFunc(func() string {
+					return "broken 3"
+				})
+			},
+			want: "broken 3",
+		},
+		{
+			name: "PanicCtx",
+			mode: WRITER,
+			f: func(g *Glg) {
+				g.PanicCtx(ContextWithFields(context.Background(), "id", 4), "broken")
+			},
+			want: "broken",
+		},
+		{
+			name: "panics when PANIC level is disabled",
+			mode: NONE,
+			f: func(g *Glg) {
+				g.PanicFunc(func() string {
+					return "silent"
+				})
+			},
+			want: "silent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLevelMode(PANIC, tt.mode)
+			r := catchPanic(func() {
+				tt.f(g)
+			})
+			if r != tt.want {
+				t.Errorf("panic value = %v, want %v", r, tt.want)
+			}
+			if tt.mode == NONE {
+				if buf.Len() != 0 {
+					t.Errorf("disabled PANIC level logged %v", buf.String())
+				}
+				return
+			}
+			if !strings.Contains(buf.String(), "[PANIC]") || !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("Panic log = %v, want %v", buf.String(), tt.want)
+			}
+			if !strings.Contains(buf.String(), "panic_test.go") {
+				t.Errorf("Panic log trace = %v, want panic_test.go", buf.String())
+			}
+		})
+	}
+}
+
+func TestGlg_PanicMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    bool
+		wantLog string
+	}{
+		{
+			name:    "text",
+			wantLog: "[PANIC]:\ta b\n",
+		},
+		{
+			name:    "json",
+			json:    true,
+			wantLog: `"detail":["a","b"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineNone)
+			if tt.json {
+				g.EnableJSON()
+			}
+			if r := catchPanic(func() {
+				g.Panic("a", "b")
+			}); r != "a b" {
+				t.Errorf("panic value = %q, want %q", r, "a b")
+			}
+			if !strings.Contains(buf.String(), tt.wantLog) {
+				t.Errorf("Panic log = %q, want %q", buf.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestPanic(t *testing.T) {
+	defer Reset()
+	buf := new(bytes.Buffer)
+	Get().SetMode(WRITER).SetWriter(buf)
+	if r := catchPanic(func() {
+		Panicf("global %s", "panic")
+	}); r != "global panic" {
+		t.Errorf("panic value = %v, want global panic", r)
+	}
+	if !strings.Contains(buf.String(), "global panic") {
+		t.Errorf("Panicf() = %v", buf.String())
+	}
+}
+
+func TestGlg_Recover(t *testing.T) {
+	tests := []struct {
+		name  string
+		f     func()
+		want  string
+		empty bool
+	}{
+		{
+			name: "panic value and stack are logged",
+			f: func() {
+				panic("boom")
+			},
+			want: "panic: boom",
+		},
+		{
+			name: "runtime error",
+			f: func() {
+				var m map[string]int
+				m["key"] = 1
+			},
+			want: "panic: assignment to entry in nil map",
+		},
+		{
+			name:  "nothing is logged without panic",
+			f:     func() {},
+			empty: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLevelLineTraceMode(ERR, TraceLineShort)
+			func() {
+				defer g.Recover(ERR)
+				tt.f()
+			}()
+			got := buf.String()
+			if tt.empty {
+				if got != "" {
+					t.Errorf("Glg.Recover() logged %v", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) || !strings.Contains(got, "goroutine ") {
+				t.Errorf("Glg.Recover() = %v, want %v with stack", got, tt.want)
+			}
+			if !regexp.MustCompile(`\(panic_test\.go:\d+\)`).MatchString(got) {
+				t.Errorf("Glg.Recover() trace = %v, want panic site", got)
+			}
+		})
+	}
+}
+
+func TestGlg_RecoverHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf)
+	h := g.RecoverHandler(ERR, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/abort" {
+			panic(http.ErrAbortHandler)
+		}
+		panic(errors.New("handler failed"))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?id=1", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	for _, want := range []string{"panic: handler failed", "method=GET", `uri="/users?id=1"`, "goroutine "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("RecoverHandler log = %v, want %v", buf.String(), want)
+		}
+	}
+
+	if r := catchPanic(func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	}); r != http.ErrAbortHandler {
+		t.Errorf("RecoverHandler() recovered %v, want http.ErrAbortHandler panicked again", r)
+	}
+}
@@ -0,0 +1,200 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncWriter is io.Writer which is safe for concurrent use
+type syncWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *syncWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func (w *syncWriter) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Len()
+}
+
+// TestGlg_ConcurrentReconfigure logs from several goroutines while the logger is reconfigured,
+// it is meant to be run with the race detector.
+func TestGlg_ConcurrentReconfigure(t *testing.T) {
+	const (
+		loggers = 4
+		updates = 200
+	)
+	other := new(syncWriter)
+	tests := []struct {
+		name        string
+		reconfigure func(g *Glg, i int)
+	}{
+		{
+			name: "SetLevel",
+			reconfigure: func(g *Glg, i int) {
+				g.SetLevel([]LEVEL{DEBG, INFO, WARN, ERR}[i%4])
+			},
+		},
+		{
+			name: "SetMode and SetLevelMode",
+			reconfigure: func(g *Glg, i int) {
+				if i%2 == 0 {
+					g.SetMode(NONE)
+				} else {
+					g.SetMode(WRITER).SetLevelMode(WARN, NONE)
+				}
+			},
+		},
+		{
+			name: "color",
+			reconfigure: func(g *Glg, i int) {
+				if i%2 == 0 {
+					g.EnableColor().DisableLevelColor(INFO)
+				} else {
+					g.DisableColor().EnableLevelColor(WARN).SetLevelColor(INFO, Green)
+				}
+			},
+		},
+		{
+			name: "writers",
+			reconfigure: func(g *Glg, i int) {
+				if i%2 == 0 {
+					g.SetLevelWriter(INFO, other).AddLevelWriter(WARN, other)
+				} else {
+					g.InitWriter().SetWriter(other)
+				}
+			},
+		},
+		{
+			name: "trace and timestamp",
+			reconfigure: func(g *Glg, i int) {
+				g.SetLineTraceMode([]traceMode{TraceLineNone, TraceLineShort, TraceLineLong}[i%3]).
+					SetLevelLineTraceMode(INFO, TraceLineNone).
+					SetCallerDepth(DefaultCallerDepth + 1 + i%2)
+				if i%2 == 0 {
+					g.DisableTimestamp().EnableLevelTimestamp(WARN).SetTimeFormat(TimeFormatMicro)
+				} else {
+					g.EnableTimestamp().DisableLevelTimestamp(INFO).SetLevelTimeFormat(WARN, TimeFormatUnix)
+				}
+				g.SetTimeLocation(time.UTC)
+			},
+		},
+		{
+			name: "encoders",
+			reconfigure: func(g *Glg, i int) {
+				switch i % 4 {
+				case 0:
+					g.EnableJSON()
+				case 1:
+					g.EnableLogfmt()
+				case 2:
+					g.DisableLogfmt().SetLevelEncoder(WARN, JSONEncoder)
+				default:
+					g.DisableJSON().SetLevelEncoder(WARN, nil)
+				}
+			},
+		},
+		{
+			name: "sampling and dedup",
+			reconfigure: func(g *Glg, i int) {
+				g.SetLevelSampler(INFO, Sample{First: 10, Thereafter: uint64(i%5 + 1), Tick: time.Millisecond}).
+					SetLevelRateLimit(WARN, float64(i+1)*100, 10).
+					SetDedup(time.Duration(i%2) * time.Millisecond)
+			},
+		},
+		{
+			name: "levels",
+			reconfigure: func(g *Glg, i int) {
+				tag := "CUSTOM" + strconv.Itoa(i%3)
+				if lv := g.TagStringToLevel(tag); lv != UNKNOWN {
+					g.RenameLevel(lv, tag+"X").RemoveLevel(lv)
+				}
+				g.AddStdLevelAt(tag, WRITER, false, SeverityOf(WARN)-1).SetPrefix(PRINT, "P"+strconv.Itoa(i))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(syncWriter)
+			g := New().SetMode(WRITER).SetWriter(w).AddStdLevel("CUSTOM0", WRITER, false)
+			ctx, cancel := context.WithCancel(context.Background())
+			var wg sync.WaitGroup
+			for n := 0; n < loggers; n++ {
+				wg.Add(1)
+				go func(n int) {
+					defer wg.Done()
+					for i := 0; ctx.Err() == nil; i++ {
+						g.Info("info", n, i)
+						g.Warnf("warn %d %d", n, i)
+						g.With("n", n).Error("error")
+						g.CustomLog("CUSTOM0", "custom")
+						g.Print("print")
+						g.GetCurrentMode(INFO)
+					}
+				}(n)
+			}
+			for i := 0; i < updates; i++ {
+				tt.reconfigure(g, i)
+			}
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func TestGlg_updateLogger(t *testing.T) {
+	g := New().SetMode(WRITER)
+	before, _ := g.logger.Load(INFO)
+	snapshot := *before
+	g.SetLevel(WARN).EnableLevelColor(INFO).SetLevelWriter(INFO, new(syncWriter)).SetLevelLineTraceMode(INFO, TraceLineLong)
+	after, _ := g.logger.Load(INFO)
+	if after == before {
+		t.Fatal("logger was updated in place")
+	}
+	if before.mode != snapshot.mode || before.isColor != snapshot.isColor ||
+		before.writer != snapshot.writer || before.traceMode != snapshot.traceMode || before.writeMode != snapshot.writeMode {
+		t.Errorf("previous logger snapshot was modified: %+v, want %+v", *before, snapshot)
+	}
+	if after.mode != NONE || after.prevMode != WRITER || !after.isColor || after.traceMode != TraceLineLong {
+		t.Errorf("updated logger = %+v", *after)
+	}
+}
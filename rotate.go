@@ -0,0 +1,440 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateFile is io.WriteCloser which writes to a file and rotates it by size or time.
+//
+// The path may contain strftime style directives (%Y, %m, %d, %H, %M, %S, %y, %j, %%),
+// then the active file name follows the current time and a new file is started
+// when the formatted name changes. Otherwise rotated files are renamed to path.<timestamp>.
+type RotateFile struct {
+	mu         sync.Mutex
+	wg         sync.WaitGroup
+	path       string
+	perm       os.FileMode
+	file       *os.File
+	name       string
+	size       int64
+	maxSize    int64
+	interval   time.Duration
+	next       time.Time
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	now        func() time.Time
+}
+
+const (
+	// RotateHourly rotates files every hour
+	RotateHourly = time.Hour
+	// RotateDaily rotates files every day
+	RotateDaily = 24 * time.Hour
+
+	rotateTimeFormat = "20060102T150405.000"
+	compressSuffix   = ".gz"
+)
+
+// NewRotateFile returns rotating file writer for path
+func NewRotateFile(path string, perm os.FileMode) *RotateFile {
+	return &RotateFile{
+		path: path,
+		perm: perm,
+		now:  time.Now,
+	}
+}
+
+// SetMaxSize sets the size in bytes which triggers rotation, 0 disables size based rotation
+func (r *RotateFile) SetMaxSize(size int64) *RotateFile {
+	r.mu.Lock()
+	r.maxSize = size
+	r.mu.Unlock()
+	return r
+}
+
+// SetRotateInterval sets the interval of time based rotation such as RotateHourly or RotateDaily.
+// Rotation happens at the boundary of the interval in local time, 0 disables time based rotation.
+func (r *RotateFile) SetRotateInterval(interval time.Duration) *RotateFile {
+	r.mu.Lock()
+	r.interval = interval
+	r.updateNext(r.now())
+	r.mu.Unlock()
+	return r
+}
+
+// SetMaxBackups sets the number of rotated files to keep, 0 keeps all files
+func (r *RotateFile) SetMaxBackups(n int) *RotateFile {
+	r.mu.Lock()
+	r.maxBackups = n
+	r.mu.Unlock()
+	return r
+}
+
+// SetMaxAge sets the age of rotated files to keep, 0 keeps all files
+func (r *RotateFile) SetMaxAge(age time.Duration) *RotateFile {
+	r.mu.Lock()
+	r.maxAge = age
+	r.mu.Unlock()
+	return r
+}
+
+// EnableCompress enables gzip compression of rotated files
+func (r *RotateFile) EnableCompress() *RotateFile {
+	r.mu.Lock()
+	r.compress = true
+	r.mu.Unlock()
+	return r
+}
+
+// DisableCompress disables gzip compression of rotated files
+func (r *RotateFile) DisableCompress() *RotateFile {
+	r.mu.Lock()
+	r.compress = false
+	r.mu.Unlock()
+	return r
+}
+
+// Write writes b to the active file, rotating it beforehand when needed
+func (r *RotateFile) Write(b []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	switch {
+	case r.file == nil:
+		err = r.open(now)
+	case r.interval > 0 && !r.next.IsZero() && !now.Before(r.next),
+		r.isPattern() && strftime(r.path, now) != r.name,
+		r.maxSize > 0 && r.size > 0 && r.size+int64(len(b)) > r.maxSize:
+		err = r.rotate(now)
+	}
+	if err != nil {
+		return 0, err
+	}
+	n, err = r.file.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the active file and starts a new one
+func (r *RotateFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rotate(r.now())
+}
+
+// Reopen closes and reopens the active file.
+// It is used after the file is moved by an external tool such as logrotate.
+func (r *RotateFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.closeFile()
+	return errors.Join(err, r.open(r.now()))
+}
+
+// Sync commits the active file contents to stable storage
+func (r *RotateFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close closes the active file and waits for running compression
+func (r *RotateFile) Close() error {
+	r.mu.Lock()
+	err := r.closeFile()
+	r.mu.Unlock()
+	r.wg.Wait()
+	return err
+}
+
+// Filename returns the name of the active file
+func (r *RotateFile) Filename() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.name == "" {
+		return r.filename(r.now())
+	}
+	return r.name
+}
+
+func (r *RotateFile) isPattern() bool {
+	return strings.Contains(r.path, "%")
+}
+
+func (r *RotateFile) filename(now time.Time) string {
+	if r.isPattern() {
+		return strftime(r.path, now)
+	}
+	return r.path
+}
+
+func (r *RotateFile) open(now time.Time) error {
+	name := r.filename(now)
+	f, err := OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, r.perm)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.Join(err, f.Close())
+	}
+	r.file = f
+	r.name = name
+	r.size = fi.Size()
+	r.updateNext(now)
+	return nil
+}
+
+// updateNext sets the next time based rotation to the boundary of the interval after now in local time
+func (r *RotateFile) updateNext(now time.Time) {
+	if r.interval <= 0 || r.file == nil {
+		r.next = time.Time{}
+		return
+	}
+	_, offset := now.Zone()
+	off := time.Duration(offset) * time.Second
+	r.next = now.Add(off).Truncate(r.interval).Add(r.interval).Add(-off)
+}
+
+func (r *RotateFile) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	r.size = 0
+	return err
+}
+
+// rotate moves the active file to the backup name if it is still the current file name
+// and opens a new file
+func (r *RotateFile) rotate(now time.Time) error {
+	name := r.name
+	err := r.closeFile()
+	if err != nil {
+		return err
+	}
+	if name != "" && (!r.isPattern() || name == r.filename(now)) {
+		backup := name + "." + now.Format(rotateTimeFormat)
+		for i := 1; fileExists(backup) || fileExists(backup+compressSuffix); i++ {
+			backup = name + "." + now.Format(rotateTimeFormat) + "." + strconv.Itoa(i)
+		}
+		err = os.Rename(name, backup)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rename file %s to %s: %w", name, backup, err)
+		}
+		if err == nil {
+			name = backup
+		}
+	}
+	err = r.open(now)
+	if err != nil {
+		return err
+	}
+	if name != "" && name != r.name && r.compress {
+		r.wg.Add(1)
+		go func(name string) {
+			defer r.wg.Done()
+			if compressFile(name) == nil {
+				r.mu.Lock()
+				r.cleanup(r.now())
+				r.mu.Unlock()
+			}
+		}(name)
+		return nil
+	}
+	return r.cleanup(now)
+}
+
+type rotatedFile struct {
+	name    string
+	modTime time.Time
+}
+
+// backups returns rotated files ordered from the newest
+func (r *RotateFile) backups() ([]rotatedFile, error) {
+	var pattern string
+	if r.isPattern() {
+		pattern = strftimeGlob(r.path) + "*"
+	} else {
+		pattern = r.path + ".*"
+	}
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]rotatedFile, 0, len(names))
+	for _, name := range names {
+		if name == r.name {
+			continue
+		}
+		fi, err := os.Stat(name)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		files = append(files, rotatedFile{name: name, modTime: fi.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	return files, nil
+}
+
+// cleanup removes rotated files exceeding max backups or max age
+func (r *RotateFile) cleanup(now time.Time) error {
+	if r.maxBackups <= 0 && r.maxAge <= 0 {
+		return nil
+	}
+	files, err := r.backups()
+	if err != nil {
+		return err
+	}
+	var errs error
+	for i, f := range files {
+		if (r.maxBackups > 0 && i >= r.maxBackups) ||
+			(r.maxAge > 0 && now.Sub(f.modTime) > r.maxAge) {
+			errs = errors.Join(errs, os.Remove(f.name))
+		}
+	}
+	return errs
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(name + compressSuffix)
+		}
+	}()
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err != nil {
+		return errors.Join(err, zw.Close(), dst.Close())
+	}
+	err = zw.Close()
+	if err != nil {
+		return errors.Join(err, dst.Close())
+	}
+	err = dst.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chtimes(name+compressSuffix, fi.ModTime(), fi.ModTime())
+	if err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+// strftime formats t by the strftime style pattern
+func strftime(pattern string, t time.Time) string {
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			sb.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y':
+			sb.WriteString(strconv.Itoa(t.Year()))
+		case 'y':
+			sb.WriteString(t.Format("06"))
+		case 'm':
+			sb.WriteString(t.Format("01"))
+		case 'd':
+			sb.WriteString(t.Format("02"))
+		case 'H':
+			sb.WriteString(t.Format("15"))
+		case 'M':
+			sb.WriteString(t.Format("04"))
+		case 'S':
+			sb.WriteString(t.Format("05"))
+		case 'j':
+			sb.WriteString(fmt.Sprintf("%03d", t.YearDay()))
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(pattern[i])
+		}
+	}
+	return sb.String()
+}
+
+// strftimeGlob converts strftime style pattern to glob pattern
+func strftimeGlob(pattern string) string {
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			sb.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y', 'y', 'm', 'd', 'H', 'M', 'S', 'j':
+			sb.WriteByte('*')
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(pattern[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,267 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package glg
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func newTestRotateFile(t *testing.T, name string, clock *fakeClock) (*RotateFile, string) {
+	t.Helper()
+	dir := t.TempDir()
+	r := NewRotateFile(filepath.Join(dir, name), 0o644)
+	r.now = clock.now
+	t.Cleanup(func() {
+		r.Close()
+	})
+	return r, dir
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRotateFile_WriteSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		writes     int
+		wantFiles  int
+	}{
+		{name: "keep all backups", writes: 4, wantFiles: 4},
+		{name: "keep one backup", maxBackups: 1, writes: 4, wantFiles: 2},
+		{name: "no rotation under max size", writes: 1, wantFiles: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Date(2024, 1, 2, 10, 30, 0, 0, time.Local)}
+			r, dir := newTestRotateFile(t, "app.log", clock)
+			r.SetMaxSize(10).SetMaxBackups(tt.maxBackups)
+			for i := 0; i < tt.writes; i++ {
+				clock.t = clock.t.Add(time.Second)
+				if _, err := r.Write([]byte("12345678")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := listFiles(t, dir); len(got) != tt.wantFiles {
+				t.Errorf("RotateFile.Write() files = %v, want %d files", got, tt.wantFiles)
+			}
+			if got := readFile(t, filepath.Join(dir, "app.log")); got != "12345678" {
+				t.Errorf("active file = %q", got)
+			}
+		})
+	}
+}
+
+func TestRotateFile_WriteInterval(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 2, 10, 30, 0, 0, time.Local)}
+	r, dir := newTestRotateFile(t, "app.log", clock)
+	r.SetRotateInterval(RotateHourly)
+	r.Write([]byte("first\n"))
+	clock.t = clock.t.Add(20 * time.Minute)
+	r.Write([]byte("second\n"))
+	if got := listFiles(t, dir); len(got) != 1 {
+		t.Errorf("rotated before interval boundary: %v", got)
+	}
+	clock.t = clock.t.Add(20 * time.Minute)
+	r.Write([]byte("third\n"))
+	got := listFiles(t, dir)
+	if len(got) != 2 {
+		t.Fatalf("not rotated after interval boundary: %v", got)
+	}
+	if got := readFile(t, filepath.Join(dir, got[1])); got != "first\nsecond\n" {
+		t.Errorf("backup file = %q", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "app.log")); got != "third\n" {
+		t.Errorf("active file = %q", got)
+	}
+}
+
+func TestRotateFile_WritePattern(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 2, 23, 59, 0, 0, time.Local)}
+	r, dir := newTestRotateFile(t, "app-%Y%m%d.log", clock)
+	r.SetMaxBackups(1)
+	for _, d := range []time.Duration{0, 2 * time.Minute, 24 * time.Hour} {
+		clock.t = clock.t.Add(d)
+		if _, err := r.Write([]byte(clock.t.Format(time.RFC3339) + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"app-20240103.log", "app-20240104.log"}
+	if got := listFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("RotateFile.Write() files = %v, want %v", got, want)
+	}
+	if got := r.Filename(); got != filepath.Join(dir, "app-20240104.log") {
+		t.Errorf("RotateFile.Filename() = %v", got)
+	}
+}
+
+func TestRotateFile_Compress(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 2, 10, 30, 0, 0, time.Local)}
+	r, dir := newTestRotateFile(t, "app.log", clock)
+	r.EnableCompress()
+	r.Write([]byte("compressed\n"))
+	if err := r.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := listFiles(t, dir)
+	if len(got) != 2 || !strings.HasSuffix(got[1], compressSuffix) {
+		t.Fatalf("rotated file is not compressed: %v", got)
+	}
+	f, err := os.Open(filepath.Join(dir, got[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "compressed\n" {
+		t.Errorf("compressed contents = %q", b)
+	}
+}
+
+func TestRotateFile_MaxAge(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	r, dir := newTestRotateFile(t, "app.log", clock)
+	r.SetMaxAge(time.Hour)
+	old := filepath.Join(dir, "app.log.old")
+	if err := os.WriteFile(old, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(old, clock.t.Add(-2*time.Hour), clock.t.Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	r.Write([]byte("new\n"))
+	if err := r.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range listFiles(t, dir) {
+		if name == "app.log.old" {
+			t.Errorf("expired backup is not removed: %v", listFiles(t, dir))
+		}
+	}
+	if got := listFiles(t, dir); len(got) != 2 {
+		t.Errorf("RotateFile.Rotate() files = %v", got)
+	}
+}
+
+func TestRotateFile_Reopen(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	r, dir := newTestRotateFile(t, "app.log", clock)
+	path := filepath.Join(dir, "app.log")
+	r.Write([]byte("before\n"))
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	r.Write([]byte("after\n"))
+	if got := readFile(t, path); got != "after\n" {
+		t.Errorf("reopened file = %q", got)
+	}
+	if got := readFile(t, path+".1"); got != "before\n" {
+		t.Errorf("moved file = %q", got)
+	}
+}
+
+func TestRotateFile_LevelWriter(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	r, dir := newTestRotateFile(t, "info.log", clock)
+	r.SetMaxSize(1)
+	g := New().SetMode(WRITER).SetLevelWriter(INFO, r)
+	g.Info("first")
+	g.Info("second")
+	if got := listFiles(t, dir); len(got) != 2 {
+		t.Errorf("files = %v", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "info.log")); !strings.Contains(got, "second") {
+		t.Errorf("active file = %q", got)
+	}
+}
+
+func Test_strftime(t *testing.T) {
+	tm := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	tests := []struct {
+		pattern string
+		want    string
+		glob    string
+	}{
+		{pattern: "app-%Y%m%d%H%M%S.log", want: "app-20240203040506.log", glob: "app-******.log"},
+		{pattern: "%y-%j-100%%", want: "24-034-100%", glob: "*-*-100%"},
+		{pattern: "%q%", want: "%q%", glob: "%q%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := strftime(tt.pattern, tm); got != tt.want {
+				t.Errorf("strftime() = %v, want %v", got, tt.want)
+			}
+			if got := strftimeGlob(tt.pattern); got != tt.glob {
+				t.Errorf("strftimeGlob() = %v, want %v", got, tt.glob)
+			}
+		})
+	}
+}
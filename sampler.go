@@ -0,0 +1,221 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample configures log sampling per tick.
+// In each Tick the First entries are logged, then every Thereafter-th entry is logged.
+// Thereafter 0 drops all entries after the First ones until the next tick.
+type Sample struct {
+	First      uint64
+	Thereafter uint64
+	Tick       time.Duration
+}
+
+// sampler limits the entries of a level by sampling and token bucket rate limiting
+// and counts suppressed entries for the summary line
+type sampler struct {
+	mu         sync.Mutex
+	sample     Sample
+	rate       float64
+	burst      float64
+	tickStart  time.Time
+	count      uint64
+	tokens     float64
+	lastRefill time.Time
+	suppressed uint64
+	reported   time.Time
+	level      LEVEL
+	gen        uint64
+	timer      *time.Timer
+	report     func(lv LEVEL, suppressed uint64)
+}
+
+const defaultSummaryInterval = time.Second
+
+// SetLevelSampler sets sampling of the level, zero Sample disables sampling.
+// The number of suppressed entries is logged as "suppressed N messages" once per Tick
+// after the first suppressed one, and on Sync.
+func (g *Glg) SetLevelSampler(lv LEVEL, sample Sample) *Glg {
+	g.updateLogger(lv, func(l *logger) {
+		s := g.newSampler(lv, l.sampler)
+		if sample.Tick <= 0 {
+			sample = Sample{}
+		}
+		s.sample = sample
+		l.sampler = s.orNil()
+	})
+	return g
+}
+
+// SetLevelRateLimit sets token bucket rate limit of the level which allows rate entries per second
+// with bursts of burst entries, non positive rate disables rate limiting.
+func (g *Glg) SetLevelRateLimit(lv LEVEL, rate float64, burst int) *Glg {
+	g.updateLogger(lv, func(l *logger) {
+		s := g.newSampler(lv, l.sampler)
+		if rate <= 0 {
+			rate, burst = 0, 0
+		} else if burst < 1 {
+			burst = 1
+		}
+		s.rate = rate
+		s.burst = float64(burst)
+		s.tokens = s.burst
+		l.sampler = s.orNil()
+	})
+	return g
+}
+
+// newSampler returns sampler of lv which has the configuration of old,
+// the suppressed entries of old are reported before it is replaced
+func (g *Glg) newSampler(lv LEVEL, old *sampler) *sampler {
+	s := &sampler{
+		level:  lv,
+		report: g.reportSuppressed,
+	}
+	if old != nil {
+		old.flush()
+		old.mu.Lock()
+		s.sample = old.sample
+		s.rate = old.rate
+		s.burst = old.burst
+		s.tokens = old.burst
+		old.mu.Unlock()
+	}
+	return s
+}
+
+// orNil returns nil when s limits nothing
+func (s *sampler) orNil() *sampler {
+	if s.sample.Tick <= 0 && s.rate <= 0 {
+		return nil
+	}
+	return s
+}
+
+// allow reports whether the entry logged at now is logged,
+// suppressed is the number of entries to be reported as suppressed before it.
+// The suppressed entries are also reported by report when the interval has passed or flush is called.
+func (s *sampler) allow(now time.Time) (ok bool, suppressed uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ok = true
+	if s.sample.Tick > 0 {
+		if now.Sub(s.tickStart) >= s.sample.Tick {
+			s.tickStart = now
+			s.count = 0
+		}
+		s.count++
+		if s.count > s.sample.First &&
+			(s.sample.Thereafter == 0 || (s.count-s.sample.First)%s.sample.Thereafter != 0) {
+			ok = false
+		}
+	}
+	if ok && s.rate > 0 {
+		if !s.lastRefill.IsZero() {
+			s.tokens += now.Sub(s.lastRefill).Seconds() * s.rate
+			if s.tokens > s.burst {
+				s.tokens = s.burst
+			}
+		}
+		s.lastRefill = now
+		if s.tokens < 1 {
+			ok = false
+		} else {
+			s.tokens--
+		}
+	}
+
+	interval := s.sample.Tick
+	if interval <= 0 {
+		interval = defaultSummaryInterval
+	}
+	if !ok {
+		if s.suppressed == 0 {
+			s.reported = now
+			gen := s.gen
+			s.timer = time.AfterFunc(interval, func() {
+				s.expire(gen)
+			})
+		}
+		s.suppressed++
+	}
+	if s.suppressed != 0 && now.Sub(s.reported) >= interval {
+		suppressed = s.take()
+	}
+	return ok, suppressed
+}
+
+// take returns the suppressed count and stops the timer reporting it, s.mu must be held
+func (s *sampler) take() (suppressed uint64) {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+		s.gen++
+	}
+	suppressed, s.suppressed = s.suppressed, 0
+	return suppressed
+}
+
+// expire reports the suppressed entries when the interval has passed and they are not taken yet
+func (s *sampler) expire(gen uint64) {
+	s.mu.Lock()
+	if gen != s.gen {
+		s.mu.Unlock()
+		return
+	}
+	s.release()
+}
+
+// flush reports the suppressed entries
+func (s *sampler) flush() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.release()
+}
+
+// release takes the suppressed count, unlocks s.mu and reports it
+func (s *sampler) release() {
+	suppressed := s.take()
+	s.mu.Unlock()
+	if suppressed != 0 {
+		s.report(s.level, suppressed)
+	}
+}
+
+// reportSuppressed writes the summary of suppressed entries to the destinations of lv
+func (g *Glg) reportSuppressed(lv LEVEL, suppressed uint64) {
+	log, ok := g.logger.Load(lv)
+	if !ok {
+		return
+	}
+	g.handleError(g.write(lv, log, time.Time{}, -1, 0, nil, "suppressed %d messages", suppressed))
+}
@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlg_SetLevelSampler(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample Sample
+		logs   int
+		want   int
+	}{
+		{name: "first only", sample: Sample{First: 3, Tick: time.Second}, logs: 10, want: 3},
+		{name: "first and thereafter", sample: Sample{First: 2, Thereafter: 3, Tick: time.Second}, logs: 11, want: 5},
+		{name: "disabled", sample: Sample{}, logs: 10, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLevelSampler(ERR, tt.sample)
+			for i := 0; i < tt.logs; i++ {
+				if err := g.Error("storm"); err != nil {
+					t.Error(err)
+				}
+			}
+			if got := strings.Count(buf.String(), "storm"); got != tt.want {
+				t.Errorf("Glg.SetLevelSampler() logged = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_SetLevelSamplerTick(t *testing.T) {
+	buf := new(bytes.Buffer)
+	clock := &fakeClock{t: time.Now()}
+	g := New().SetMode(WRITER).SetWriter(buf).SetClock(clock.now).SetLevelSampler(ERR, Sample{First: 1, Tick: time.Minute})
+	for i := 0; i < 5; i++ {
+		g.Error("storm")
+	}
+	clock.t = clock.t.Add(time.Minute)
+	g.Error("next tick")
+	got := buf.String()
+	if strings.Count(got, "storm") != 1 || !strings.Contains(got, "next tick") {
+		t.Errorf("Glg.SetLevelSampler() = %v", got)
+	}
+	if !strings.Contains(got, "suppressed 4 messages") {
+		t.Errorf("suppressed summary is not logged: %v", got)
+	}
+	if strings.Index(got, "suppressed 4 messages") > strings.Index(got, "next tick") {
+		t.Errorf("suppressed summary is logged after the entry: %v", got)
+	}
+}
+
+func TestGlg_SetLevelSamplerSummary(t *testing.T) {
+	t.Run("reported by sync", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		g := New().SetMode(WRITER).SetWriter(buf).SetLevelSampler(ERR, Sample{First: 1, Tick: time.Minute})
+		for i := 0; i < 3; i++ {
+			g.Error("storm")
+		}
+		if err := g.Sync(); err != nil {
+			t.Error(err)
+		}
+		if got := buf.String(); !strings.Contains(got, "suppressed 2 messages") {
+			t.Errorf("suppressed summary is not logged by Glg.Sync(): %v", got)
+		}
+	})
+	t.Run("reported after tick", func(t *testing.T) {
+		w := new(syncWriter)
+		g := New().SetMode(WRITER).SetWriter(w).SetLevelSampler(ERR, Sample{First: 1, Tick: 10 * time.Millisecond})
+		for i := 0; i < 3; i++ {
+			g.Error("storm")
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(w.String(), "suppressed 2 messages") {
+			if time.Now().After(deadline) {
+				t.Fatalf("suppressed summary is not logged after the tick: %v", w.String())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
+
+func TestGlg_SetLevelRateLimit(t *testing.T) {
+	buf := new(bytes.Buffer)
+	clock := &fakeClock{t: time.Now()}
+	g := New().SetMode(WRITER).SetWriter(buf).SetClock(clock.now).SetLevelRateLimit(WARN, 2, 3)
+	for i := 0; i < 10; i++ {
+		g.Warn("burst")
+	}
+	if got := strings.Count(buf.String(), "burst"); got != 3 {
+		t.Errorf("Glg.SetLevelRateLimit() burst logged = %d, want 3", got)
+	}
+	clock.t = clock.t.Add(time.Second)
+	for i := 0; i < 10; i++ {
+		g.Warn("refill")
+	}
+	got := buf.String()
+	if n := strings.Count(got, "refill"); n != 2 {
+		t.Errorf("Glg.SetLevelRateLimit() refill logged = %d, want 2", n)
+	}
+	if !strings.Contains(got, "suppressed 7 messages") {
+		t.Errorf("suppressed summary is not logged: %v", got)
+	}
+	g.Info("unlimited")
+	if !strings.Contains(buf.String(), "unlimited") {
+		t.Error("rate limit affected other level")
+	}
+	g.SetLevelRateLimit(WARN, 0, 0)
+	if l, _ := g.logger.Load(WARN); l.sampler != nil {
+		t.Error("Glg.SetLevelRateLimit() did not disable rate limit")
+	}
+}
@@ -0,0 +1,67 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "os"
+
+// Severity is the order of levels used by level threshold filtering, larger is more severe
+type Severity int32
+
+// SeverityStep is the severity distance between adjacent standard levels.
+// The severity of a standard level is its LEVEL value times SeverityStep, e.g. DEBG is 100 and WARN is 700,
+// so custom levels can be registered between them.
+// PANIC is the exception, it is placed halfway between FAIL and FATAL.
+const SeverityStep Severity = 100
+
+// SeverityOf returns the default severity of lv, custom levels added by AddStdLevel and AddErrLevel have this severity
+func SeverityOf(lv LEVEL) Severity {
+	if lv == PANIC {
+		return SeverityOf(FATAL) - SeverityStep/2
+	}
+	return Severity(lv) * SeverityStep
+}
+
+// Severity returns the severity of lv used by SetLevel and OverflowDropBelow
+func (g *Glg) Severity(lv LEVEL) Severity {
+	l, ok := g.logger.Load(lv)
+	if ok {
+		return l.severity
+	}
+	return SeverityOf(lv)
+}
+
+// AddStdLevelAt adds std log level ordered by sev,
+// e.g. AddStdLevelAt("NOTICE", STD, false, SeverityOf(WARN)-1) adds NOTICE just below WARN.
+func (g *Glg) AddStdLevelAt(tag string, mode MODE, isColor bool, sev Severity) *Glg {
+	_, err := g.addLevel(tag, mode, isColor, os.Stdout, &sev)
+	g.handleError(err)
+	return g
+}
+
+// AddErrLevelAt adds error log level ordered by sev
+func (g *Glg) AddErrLevelAt(tag string, mode MODE, isColor bool, sev Severity) *Glg {
+	_, err := g.addLevel(tag, mode, isColor, os.Stderr, &sev)
+	g.handleError(err)
+	return g
+}
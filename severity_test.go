@@ -0,0 +1,181 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGlg_AddStdLevelAt(t *testing.T) {
+	newLogger := func(buf *bytes.Buffer) *Glg {
+		return New().
+			AddStdLevelAt("NOTICE", WRITER, false, SeverityOf(WARN)-1).
+			AddStdLevelAt("VERBOSE", WRITER, false, SeverityOf(DEBG)-1).
+			AddStdLevel("AUDIT", WRITER, false).
+			SetMode(WRITER).
+			SetWriter(buf).
+			DisableTimestamp().
+			SetLineTraceMode(TraceLineNone)
+	}
+	tests := []struct {
+		name  string
+		level string
+		want  map[string]bool
+	}{
+		{
+			name:  "no threshold",
+			level: "",
+			want:  map[string]bool{"VERBOSE": true, "DEBG": true, "INFO": true, "NOTICE": true, "WARN": true, "AUDIT": true},
+		},
+		{
+			name:  "DEBG filters VERBOSE",
+			level: "DEBG",
+			want:  map[string]bool{"VERBOSE": false, "DEBG": true, "INFO": true, "NOTICE": true, "WARN": true, "AUDIT": true},
+		},
+		{
+			name:  "WARN filters NOTICE",
+			level: "WARN",
+			want:  map[string]bool{"VERBOSE": false, "DEBG": false, "INFO": false, "NOTICE": false, "WARN": true, "AUDIT": true},
+		},
+		{
+			name:  "NOTICE as threshold",
+			level: "NOTICE",
+			want:  map[string]bool{"VERBOSE": false, "DEBG": false, "INFO": false, "NOTICE": true, "WARN": true, "AUDIT": true},
+		},
+		{
+			name:  "custom level without severity is above FATAL",
+			level: "AUDIT",
+			want:  map[string]bool{"VERBOSE": false, "DEBG": false, "INFO": false, "NOTICE": false, "WARN": false, "AUDIT": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := newLogger(buf)
+			if tt.level != "" {
+				g.SetLevel(g.TagStringToLevel(tt.level))
+			}
+			for tag, want := range tt.want {
+				buf.Reset()
+				lv := g.TagStringToLevel(tag)
+				if lv == UNKNOWN {
+					t.Fatalf("TagStringToLevel(%q) = UNKNOWN", tag)
+				}
+				if got := g.isModeEnable(lv); got != want {
+					t.Errorf("isModeEnable(%s) = %v, want %v", tag, got, want)
+				}
+				if err := g.CustomLog(tag, "msg"); err != nil {
+					t.Fatal(err)
+				}
+				if got := buf.Len() != 0; got != want {
+					t.Errorf("%s logged = %v, want %v: %q", tag, got, want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestGlg_Severity(t *testing.T) {
+	g := New().AddErrLevelAt("notice", WRITER, false, SeverityOf(INFO)+50).AddStdLevel("audit", WRITER, false)
+	tests := []struct {
+		name string
+		lv   LEVEL
+		want Severity
+	}{
+		{
+			name: "standard level",
+			lv:   WARN,
+			want: 700,
+		},
+		{
+			name: "panic level is between fail and fatal",
+			lv:   PANIC,
+			want: 950,
+		},
+		{
+			name: "custom level with severity",
+			lv:   g.TagStringToLevel("NOTICE"),
+			want: 550,
+		},
+		{
+			name: "custom level without severity",
+			lv:   g.TagStringToLevel("AUDIT"),
+			want: SeverityOf(FATAL + 2),
+		},
+		{
+			name: "unknown level",
+			lv:   UNKNOWN,
+			want: SeverityOf(UNKNOWN),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Severity(tt.lv); got != tt.want {
+				t.Errorf("Glg.Severity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_AddStdLevelAtJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().
+		AddStdLevelAt("NOTICE", WRITER, false, SeverityOf(WARN)-1).
+		SetMode(WRITER).
+		SetWriter(buf).
+		DisableTimestamp().
+		SetLineTraceMode(TraceLineNone).
+		EnableJSON().
+		SetLevel(INFO)
+	if err := g.CustomLog("notice", "msg"); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"NOTICE","detail":"msg"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CustomLog() = %q, want %q", got, want)
+	}
+}
+
+func TestOverflowDropBelowSeverity(t *testing.T) {
+	w := newBlockWriter()
+	g := New().
+		AddStdLevelAt("NOTICE", WRITER, false, SeverityOf(WARN)-1).
+		SetMode(WRITER).
+		SetWriter(w).
+		EnableAsync(1, OverflowDropBelow(WARN))
+	g.Warn("first")
+	<-w.started
+	g.Warn("queued")
+	g.CustomLog("NOTICE", "dropped")
+	if got := g.AsyncDropped(); got != 1 {
+		t.Errorf("Glg.AsyncDropped() = %d, want 1", got)
+	}
+	close(w.release)
+	g.Close()
+	if got := w.String(); strings.Contains(got, "dropped") {
+		t.Errorf("output %v contains dropped entry", got)
+	}
+}
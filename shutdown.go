@@ -0,0 +1,206 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+)
+
+// exitTimeout is the time limit of each of the flush of the queued entries, the exit handlers
+// and the sync of the writers run by Fatal before the program exits.
+var exitTimeout = 5 * time.Second
+
+// Syncer is writer which can commit the written entries to its storage like *os.File
+type Syncer interface {
+	Sync() error
+}
+
+// RegisterExitHandler registers handler run before Fatal exits the program.
+// Handlers are run in the registered order and the program exits after 5 seconds
+// even if they have not finished, so that a hung handler cannot block the termination.
+func (g *Glg) RegisterExitHandler(handler func()) *Glg {
+	if handler == nil {
+		return g
+	}
+	for {
+		old := g.exitHandlers.Load()
+		var hs []func()
+		if old != nil {
+			hs = make([]func(), len(*old), len(*old)+1)
+			copy(hs, *old)
+		}
+		hs = append(hs, handler)
+		if g.exitHandlers.CompareAndSwap(old, &hs) {
+			return g
+		}
+	}
+}
+
+// Sync reports pending suppressed and repeated entries, writes all queued entries
+// and commits every registered writer implementing Syncer
+func (g *Glg) Sync() error {
+	var errs []error
+	g.flushSummaries()
+	if err := g.Flush(context.Background()); err != nil {
+		errs = append(errs, err)
+	}
+	for _, w := range g.writers() {
+		if s, ok := w.(Syncer); ok {
+			if err := s.Sync(); err != nil {
+				errs = append(errs, fmt.Errorf("sync error: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flushSummaries reports the suppressed and the repeated entries pending in every level
+func (g *Glg) flushSummaries() {
+	g.logger.Range(func(_ LEVEL, l *logger) bool {
+		l.sampler.flush()
+		l.dedup.flush()
+		return true
+	})
+}
+
+// Close writes all queued entries, stops background writers,
+// then syncs and closes every registered writer implementing Syncer or io.Closer.
+// Standard output and standard error are never closed.
+// The closed writers are left registered, so the instance should not log to them after Close.
+func (g *Glg) Close() error {
+	g.DisableAsync()
+	errs := []error{g.Sync()}
+	for _, w := range g.writers() {
+		if c, ok := w.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("close error: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisterExitHandler registers handler run before Fatal exits the program
+func RegisterExitHandler(handler func()) *Glg {
+	return glg.RegisterExitHandler(handler)
+}
+
+// Sync writes all queued entries and commits every registered writer implementing Syncer
+func Sync() error {
+	return glg.Sync()
+}
+
+// Close writes all queued entries, stops background writers and closes the registered writers
+func Close() error {
+	return glg.Close()
+}
+
+// writers returns the distinct writers of all levels except standard output and standard error
+func (g *Glg) writers() (ws []io.Writer) {
+	seen := make(map[interface{}]bool)
+	var add func(w io.Writer)
+	add = func(w io.Writer) {
+		switch w := w.(type) {
+		case nil:
+			return
+		case multiWriter:
+			for _, mw := range w {
+				add(mw)
+			}
+			return
+		case *os.File:
+			if w == os.Stdout || w == os.Stderr {
+				return
+			}
+		}
+		if reflect.TypeOf(w).Comparable() {
+			if seen[w] {
+				return
+			}
+			seen[w] = true
+		}
+		ws = append(ws, w)
+	}
+	g.logger.Range(func(_ LEVEL, l *logger) bool {
+		add(l.writer)
+		add(l.std)
+		return true
+	})
+	return ws
+}
+
+// fatalExit writes the queued entries, runs the exit handlers, then writes the entries logged by them
+// and syncs the writers before exiting the program.
+// Each step is given up after exitTimeout, so a hung exit handler cannot keep the queued entries from being written.
+func (g *Glg) fatalExit(code int) {
+	ctx, cancel := context.WithTimeout(context.Background(), exitTimeout)
+	if err := g.Flush(ctx); err != nil {
+		g.handleError(fmt.Errorf("queued entries were not written in %v: %w", exitTimeout, err))
+	}
+	cancel()
+	g.waitExit("exit handlers", g.runExitHandlers)
+	g.waitExit("sync of the writers", func() {
+		g.handleError(g.Sync())
+	})
+	exit(code)
+}
+
+// waitExit runs f and gives up waiting for it after exitTimeout
+func (g *Glg) waitExit(name string, f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	timer := time.NewTimer(exitTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		g.handleError(fmt.Errorf("%s did not finish in %v", name, exitTimeout))
+	}
+}
+
+func (g *Glg) runExitHandlers() {
+	hs := g.exitHandlers.Load()
+	if hs == nil {
+		return
+	}
+	for _, h := range *hs {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					g.handleError(fmt.Errorf("exit handler panic: %v", r))
+				}
+			}()
+			h()
+		}()
+	}
+}
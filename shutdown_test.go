@@ -0,0 +1,251 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncCloser records Sync and Close calls
+type syncCloser struct {
+	bytes.Buffer
+	err    error
+	syncs  int
+	closes int
+}
+
+func (s *syncCloser) Sync() error {
+	s.syncs++
+	return s.err
+}
+
+func (s *syncCloser) Close() error {
+	s.closes++
+	return s.err
+}
+
+func TestGlg_Sync(t *testing.T) {
+	errSync := errors.New("sync failed")
+	tests := []struct {
+		name    string
+		writers []*syncCloser
+		setup   func(g *Glg, ws []*syncCloser)
+		want    []int
+		wantErr error
+	}{
+		{
+			name:    "writer shared by all levels is synced once",
+			writers: []*syncCloser{{}},
+			setup: func(g *Glg, ws []*syncCloser) {
+				g.SetWriter(ws[0])
+			},
+			want: []int{1},
+		},
+		{
+			name:    "every writer of multi writer is synced",
+			writers: []*syncCloser{{}, {}},
+			setup: func(g *Glg, ws []*syncCloser) {
+				g.SetWriter(ws[0]).AddLevelWriter(ERR, ws[1])
+			},
+			want: []int{1, 1},
+		},
+		{
+			name:    "sync error is returned",
+			writers: []*syncCloser{{err: errSync}},
+			setup: func(g *Glg, ws []*syncCloser) {
+				g.SetLevelWriter(INFO, ws[0])
+			},
+			want:    []int{1},
+			wantErr: errSync,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New()
+			tt.setup(g, tt.writers)
+			err := g.Sync()
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Glg.Sync() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for i, w := range tt.writers {
+				if w.syncs != tt.want[i] {
+					t.Errorf("writer %d synced %d times, want %d", i, w.syncs, tt.want[i])
+				}
+				if w.closes != 0 {
+					t.Errorf("writer %d closed by Glg.Sync()", i)
+				}
+			}
+		})
+	}
+}
+
+func TestGlg_Close(t *testing.T) {
+	w := new(syncCloser)
+	g := New().SetMode(WRITER).SetWriter(w).AddLevelWriter(ERR, w).EnableAsync(10, OverflowBlock)
+	g.Info("queued")
+	if err := g.Close(); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(w.String(), "queued") {
+		t.Errorf("Glg.Close() did not drain queue: %v", w.String())
+	}
+	if w.syncs != 1 || w.closes != 1 {
+		t.Errorf("Glg.Close() synced %d and closed %d times, want 1 and 1", w.syncs, w.closes)
+	}
+
+	errClose := errors.New("close failed")
+	if err := New().SetWriter(&syncCloser{err: errClose}).Close(); !errors.Is(err, errClose) {
+		t.Errorf("Glg.Close() error = %v, want %v", err, errClose)
+	}
+}
+
+func TestGlg_writers(t *testing.T) {
+	w1, w2 := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetWriter(w1).AddLevelWriter(WARN, w2).SetLevelWriter(ERR, os.Stdout)
+	got := g.writers()
+	want := []io.Writer{w1, w2}
+	if len(got) != len(want) {
+		t.Fatalf("Glg.writers() = %v, want %v", got, want)
+	}
+	for _, w := range want {
+		found := false
+		for _, gw := range got {
+			found = found || gw == w
+		}
+		if !found {
+			t.Errorf("Glg.writers() = %v, does not contain %p", got, w)
+		}
+	}
+}
+
+func TestGlg_RegisterExitHandler(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(s string) func() {
+		return func() {
+			mu.Lock()
+			calls = append(calls, s)
+			mu.Unlock()
+		}
+	}
+	var handled []error
+	w := new(syncCloser)
+	g := New().SetMode(WRITER).SetWriter(w).
+		SetErrorHandler(func(err error) {
+			handled = append(handled, err)
+		}).
+		RegisterExitHandler(record("first")).
+		RegisterExitHandler(nil).
+		RegisterExitHandler(func() {
+			panic("broken handler")
+		}).
+		RegisterExitHandler(record("second"))
+
+	if err := testExit(1, func() {
+		g.Fatal("fatal")
+	}); err != nil {
+		t.Error(err)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("exit handlers called %v, want %v", calls, want)
+	}
+	if len(handled) != 1 || !strings.Contains(handled[0].Error(), "broken handler") {
+		t.Errorf("exit handler panic reported as %v", handled)
+	}
+	if w.syncs != 1 {
+		t.Errorf("writer synced %d times before exit, want 1", w.syncs)
+	}
+}
+
+// slowWriter writes after delay
+type slowWriter struct {
+	syncWriter
+	delay time.Duration
+}
+
+func (s *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(s.delay)
+	return s.syncWriter.Write(p)
+}
+
+func TestGlg_fatalExitFlushFirst(t *testing.T) {
+	defer func(d time.Duration) {
+		exitTimeout = d
+	}(exitTimeout)
+	exitTimeout = 200 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+	w := &slowWriter{delay: 20 * time.Millisecond}
+	written := make(chan string, 1)
+	g := New().SetMode(WRITER).SetWriter(w).EnableAsync(10, OverflowBlock).
+		RegisterExitHandler(func() {
+			written <- w.String()
+			<-release
+		})
+	defer g.DisableAsync()
+	if err := testExit(1, func() {
+		g.Fatal("fatal")
+	}); err != nil {
+		t.Error(err)
+	}
+	if got := <-written; !strings.Contains(got, "fatal") {
+		t.Errorf("queued entries are not written before the exit handlers: %q", got)
+	}
+}
+
+func TestGlg_fatalExitTimeout(t *testing.T) {
+	defer func(d time.Duration) {
+		exitTimeout = d
+	}(exitTimeout)
+	exitTimeout = 10 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+	var handled []error
+	g := New().SetMode(NONE).
+		SetErrorHandler(func(err error) {
+			handled = append(handled, err)
+		}).
+		RegisterExitHandler(func() {
+			<-release
+		})
+	if err := testExit(1, func() {
+		g.fatalExit(1)
+	}); err != nil {
+		t.Error(err)
+	}
+	if len(handled) != 1 {
+		t.Errorf("hung exit handler reported as %v", handled)
+	}
+}
@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// SlogHandler is slog.Handler which writes records through glg instance
+type SlogHandler struct {
+	g      *Glg
+	levels []slogLevel
+	group  string
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
+
+type slogLevel struct {
+	slevel slog.Level
+	level  LEVEL
+}
+
+// NewSlogHandler returns slog.Handler backed by glg instance.
+// slog.LevelDebug, LevelInfo, LevelWarn and LevelError are mapped to DEBG, INFO, WARN and ERR.
+func (g *Glg) NewSlogHandler() *SlogHandler {
+	return &SlogHandler{
+		g: g,
+		levels: []slogLevel{
+			{slevel: slog.LevelDebug, level: DEBG},
+			{slevel: slog.LevelInfo, level: INFO},
+			{slevel: slog.LevelWarn, level: WARN},
+			{slevel: slog.LevelError, level: ERR},
+		},
+	}
+}
+
+// NewSlogHandler returns slog.Handler backed by glg instance
+func NewSlogHandler() *SlogHandler {
+	return glg.NewSlogHandler()
+}
+
+// MapLevel returns handler which maps slog level sl and above to glg level lv.
+// Records are logged with the level mapped to the nearest slog level at or below them,
+// so custom levels registered by AddStdLevel or AddErrLevel can be placed between standard levels.
+func (h *SlogHandler) MapLevel(sl slog.Level, lv LEVEL) *SlogHandler {
+	levels := make([]slogLevel, 0, len(h.levels)+1)
+	for _, l := range h.levels {
+		if l.slevel != sl {
+			levels = append(levels, l)
+		}
+	}
+	levels = append(levels, slogLevel{slevel: sl, level: lv})
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].slevel < levels[j].slevel
+	})
+	nh := *h
+	nh.levels = levels
+	return &nh
+}
+
+// Level returns glg level for slog level
+func (h *SlogHandler) Level(sl slog.Level) LEVEL {
+	for i := len(h.levels) - 1; i >= 0; i-- {
+		if h.levels[i].slevel <= sl {
+			return h.levels[i].level
+		}
+	}
+	if len(h.levels) != 0 {
+		return h.levels[0].level
+	}
+	return UNKNOWN
+}
+
+// Enabled reports whether the glg level mapped from sl is logging
+func (h *SlogHandler) Enabled(_ context.Context, sl slog.Level) bool {
+	return h.g.isModeEnable(h.Level(sl))
+}
+
+// Handle writes the record with handler and record attributes and the fields extracted from ctx,
+// the entry has the time of the record unless it is zero.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := h.g.contextFields(ctx)
+	if r.NumAttrs() != 0 {
+		fields = fields[:len(fields):len(fields)]
+		r.Attrs(func(a slog.Attr) bool {
+			fields = appendSlogAttr(fields, h.group, a)
+			return true
+		})
+	}
+	return h.g.outputAt(h.Level(r.Level), r.Time, -1, r.PC, fields, "%s", r.Message)
+}
+
+// WithAttrs returns handler which attaches attrs to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make([]Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, h.group, a)
+	}
+	nh := *h
+	nh.g = h.g.With(fields)
+	return &nh
+}
+
+// WithGroup returns handler which qualifies following attribute keys by name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	nh := *h
+	nh.group = h.group + name + "."
+	return &nh
+}
+
+func appendSlogAttr(fields []Field, group string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, ga := range attrs {
+			fields = appendSlogAttr(fields, group, ga)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: group + a.Key, Value: a.Value.Any()})
+}
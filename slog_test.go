@@ -0,0 +1,169 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler_Level(t *testing.T) {
+	g := New()
+	g.AddStdLevel("NOTICE", STD, false)
+	notice := g.TagStringToLevel("NOTICE")
+	h := g.NewSlogHandler().MapLevel(slog.LevelInfo+2, notice)
+	tests := []struct {
+		name   string
+		slevel slog.Level
+		want   LEVEL
+	}{
+		{name: "below debug", slevel: slog.LevelDebug - 4, want: DEBG},
+		{name: "debug", slevel: slog.LevelDebug, want: DEBG},
+		{name: "info", slevel: slog.LevelInfo, want: INFO},
+		{name: "info+1", slevel: slog.LevelInfo + 1, want: INFO},
+		{name: "custom", slevel: slog.LevelInfo + 2, want: notice},
+		{name: "warn", slevel: slog.LevelWarn, want: WARN},
+		{name: "error", slevel: slog.LevelError, want: ERR},
+		{name: "above error", slevel: slog.LevelError + 4, want: ERR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.Level(tt.slevel); got != tt.want {
+				t.Errorf("SlogHandler.Level() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := g.NewSlogHandler().Level(slog.LevelInfo + 2); got != INFO {
+		t.Errorf("MapLevel modified original handler, got %v", got)
+	}
+}
+
+func TestSlogHandler_Enabled(t *testing.T) {
+	g := New().SetLevel(WARN)
+	h := g.NewSlogHandler()
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("SlogHandler.Enabled() = true for filtered level")
+	}
+	if !h.Enabled(context.Background(), slog.LevelError) {
+		t.Error("SlogHandler.Enabled() = false for enabled level")
+	}
+}
+
+func TestSlogHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want string
+	}{
+		{
+			name: "message and attrs",
+			log: func(l *slog.Logger) {
+				l.Info("hello", "user", "kpango", "age", 30)
+			},
+			want: "[INFO]:\thello\tuser=kpango\tage=30\n",
+		},
+		{
+			name: "with attrs",
+			log: func(l *slog.Logger) {
+				l.With("req", "abc").Warn("hello", "n", 1)
+			},
+			want: "[WARN]:\thello\treq=abc\tn=1\n",
+		},
+		{
+			name: "with group",
+			log: func(l *slog.Logger) {
+				l.With("req", "abc").WithGroup("http").With("method", "GET").Error("hello", slog.Group("res", "code", 500))
+			},
+			want: "[ERR]:\thello\treq=abc\thttp.method=GET\thttp.res.code=500\n",
+		},
+		{
+			name: "empty group is ignored",
+			log: func(l *slog.Logger) {
+				l.WithGroup("g").Info("hello", slog.Group("empty"))
+			},
+			want: "[INFO]:\thello\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineNone)
+			tt.log(slog.New(g.NewSlogHandler()))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("SlogHandler.Handle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlogHandler_HandleTime(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		time time.Time
+		want string
+	}{
+		{name: "record time", time: at, want: "2020-01-02 03:04:05\t[INFO]:\tmsg\n"},
+		{name: "zero record time", want: "2030-06-07 08:09:10\t[INFO]:\tmsg\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			now := time.Date(2030, 6, 7, 8, 9, 10, 0, time.UTC)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).
+				SetClock(func() time.Time { return now }).SetTimeLocation(time.UTC)
+			r := slog.NewRecord(tt.time, slog.LevelInfo, "msg", 0)
+			if err := g.NewSlogHandler().Handle(context.Background(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("SlogHandler.Handle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlogHandler_HandleLineTrace(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLevelLineTraceMode(INFO, TraceLineShort)
+	slog.New(g.NewSlogHandler()).Info("trace")
+	if !strings.Contains(buf.String(), "(slog_test.go:") {
+		t.Errorf("SlogHandler.Handle() line trace = %v", buf.String())
+	}
+}
+
+func TestSlogHandler_HandleContext(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).AddContextExtractor(func(ctx context.Context) []Field {
+		return []Field{{Key: "request_id", Value: ctx.Value(requestIDKey{})}}
+	})
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	slog.New(g.NewSlogHandler()).InfoContext(ctx, "sample", "k", "v")
+	if !strings.HasSuffix(buf.String(), "sample\trequest_id=req-1\tk=v\n") {
+		t.Errorf("SlogHandler.Handle() = %v", buf.String())
+	}
+}
@@ -0,0 +1,194 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// maxLineSize is the size of incomplete line which LineWriter logs without waiting for newline
+const maxLineSize = 64 << 10
+
+// LineWriter is io.WriteCloser which logs each line written to it as an entry of its level.
+// Incomplete last line is kept until the newline is written or the writer is closed, empty lines are skipped.
+type LineWriter struct {
+	g      *Glg
+	level  LEVEL
+	detect bool
+	mu     sync.Mutex
+	buf    []byte
+}
+
+// LevelWriter returns LineWriter which logs the lines written to it as entries of lv,
+// such as the output of sub processes or libraries which only accept io.Writer.
+func (g *Glg) LevelWriter(lv LEVEL) *LineWriter {
+	return &LineWriter{
+		g:     g,
+		level: lv,
+	}
+}
+
+// StdLogger returns log.Logger of the standard library which logs to lv, such as http.Server.ErrorLog
+func (g *Glg) StdLogger(lv LEVEL) *log.Logger {
+	return log.New(g.LevelWriter(lv), "", 0)
+}
+
+// RedirectStdLog makes the standard log package log to lv and returns function which restores the previous output, prefix and flags
+func (g *Glg) RedirectStdLog(lv LEVEL) (restore func()) {
+	w := g.LevelWriter(lv)
+	out, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	log.SetOutput(w)
+	log.SetPrefix("")
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(out)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+		w.Close()
+	}
+}
+
+// LevelWriter returns LineWriter which logs the lines written to it as entries of lv
+func LevelWriter(lv LEVEL) *LineWriter {
+	return glg.LevelWriter(lv)
+}
+
+// StdLogger returns log.Logger of the standard library which logs to lv
+func StdLogger(lv LEVEL) *log.Logger {
+	return glg.StdLogger(lv)
+}
+
+// RedirectStdLog makes the standard log package log to lv and returns function which restores it
+func RedirectStdLog(lv LEVEL) (restore func()) {
+	return glg.RedirectStdLog(lv)
+}
+
+// EnableLevelDetection makes the writer log the lines starting with a level tag such as "[ERROR] msg" or "WARN: msg"
+// at the level of the tag without the tag, the tags are converted by TagStringToLevel.
+func (w *LineWriter) EnableLevelDetection() *LineWriter {
+	w.mu.Lock()
+	w.detect = true
+	w.mu.Unlock()
+	return w
+}
+
+// Write logs the complete lines of b and keeps the incomplete last line
+func (w *LineWriter) Write(b []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, b...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if lerr := w.log(w.buf[:i]); lerr != nil && err == nil {
+			err = lerr
+		}
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= maxLineSize {
+		if lerr := w.log(w.buf); lerr != nil && err == nil {
+			err = lerr
+		}
+		w.buf = w.buf[:0]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(b), err
+}
+
+// Close logs the incomplete last line
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.log(w.buf)
+	w.buf = nil
+	return err
+}
+
+func (w *LineWriter) log(line []byte) error {
+	msg := strings.TrimSuffix(string(line), "\r")
+	if strings.TrimSpace(msg) == "" {
+		return nil
+	}
+	lv := w.level
+	if w.detect {
+		if dl, rest, ok := w.g.detectLevel(msg); ok {
+			lv, msg = dl, rest
+		}
+	}
+	return w.g.output(lv, -1, stdLogCaller(), w.g.fields, "%s", msg)
+}
+
+// detectLevel returns the level of the tag at the beginning of line such as "[ERROR] msg" or "WARN: msg" and the rest of line
+func (g *Glg) detectLevel(line string) (lv LEVEL, rest string, ok bool) {
+	s := strings.TrimLeft(line, " \t")
+	var tag string
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return UNKNOWN, line, false
+		}
+		tag, rest = s[1:end], strings.TrimPrefix(s[end+1:], ":")
+	} else {
+		end := strings.IndexByte(s, ':')
+		if end <= 0 || strings.ContainsAny(s[:end], " \t") {
+			return UNKNOWN, line, false
+		}
+		tag, rest = s[:end], s[end+1:]
+	}
+	lv = g.TagStringToLevel(tag)
+	if lv == UNKNOWN {
+		return UNKNOWN, line, false
+	}
+	return lv, strings.TrimLeft(rest, " \t"), true
+}
+
+// stdLogCaller returns pc of the caller of the standard log package, or zero when it is not called by the log package
+func stdLogCaller() uintptr {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	inLog := false
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "log.") {
+			inLog = true
+		} else if inLog {
+			return frame.PC + 1
+		}
+		if !more {
+			return 0
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"log"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newLineWriterLogger(buf *bytes.Buffer) *Glg {
+	return New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineNone)
+}
+
+func TestLineWriter_Write(t *testing.T) {
+	tests := []struct {
+		name   string
+		detect bool
+		writes []string
+		want   string
+	}{
+		{
+			name:   "lines",
+			writes: []string{"first\nsec", "ond\r\n\n  \nthird"},
+			want:   "[INFO]:\tfirst\n[INFO]:\tsecond\n[INFO]:\tthird\n",
+		},
+		{
+			name:   "without detection",
+			writes: []string{"[ERROR] boom\n"},
+			want:   "[INFO]:\t[ERROR] boom\n",
+		},
+		{
+			name:   "detection",
+			detect: true,
+			writes: []string{"[ERROR] boom\nWARN: careful\n [dbg]: details\nok\n"},
+			want:   "[ERR]:\tboom\n[WARN]:\tcareful\n[DEBG]:\tdetails\n[INFO]:\tok\n",
+		},
+		{
+			name:   "unknown tags are kept",
+			detect: true,
+			writes: []string{"[unknown] x\nhttp: TLS handshake error\nno tag: here\n[unclosed\n"},
+			want:   "[INFO]:\t[unknown] x\n[INFO]:\thttp: TLS handshake error\n[INFO]:\tno tag: here\n[INFO]:\t[unclosed\n",
+		},
+		{
+			name:   "long line",
+			writes: []string{strings.Repeat("a", maxLineSize), "b\n"},
+			want:   "[INFO]:\t" + strings.Repeat("a", maxLineSize) + "\n[INFO]:\tb\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			w := newLineWriterLogger(buf).LevelWriter(INFO)
+			if tt.detect {
+				w.EnableLevelDetection()
+			}
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil || n != len(s) {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(s))
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_StdLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := newLineWriterLogger(buf).SetLevelLineTraceMode(WARN, TraceLineShort)
+	l := g.StdLogger(WARN)
+	l.Printf("request %d failed", 1)
+	l.Println("multi\nline")
+	want := `^\[WARN\]:\t\(stdlog_test\.go:\d+\):\trequest 1 failed\n` +
+		`\[WARN\]:\t\(stdlog_test\.go:\d+\):\tmulti\n\[WARN\]:\t\(stdlog_test\.go:\d+\):\tline\n$`
+	if got := buf.String(); !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("output = %q, want match %s", got, want)
+	}
+}
+
+func TestGlg_RedirectStdLog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	out, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	restore := newLineWriterLogger(buf).RedirectStdLog(ERR)
+	log.Print("redirected")
+	restore()
+	if got, want := buf.String(), "[ERR]:\tredirected\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if log.Writer() != out || log.Prefix() != prefix || log.Flags() != flags {
+		t.Error("RedirectStdLog() restore did not restore the standard logger")
+	}
+}
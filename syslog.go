@@ -0,0 +1,446 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogSeverity is syslog message severity
+type SyslogSeverity uint8
+
+// SyslogFacility is syslog message facility
+type SyslogFacility uint8
+
+// SyslogFormat is syslog message format
+type SyslogFormat uint8
+
+type syslogFraming uint8
+
+const (
+	// SeverityEmerg is system is unusable
+	SeverityEmerg SyslogSeverity = iota
+	// SeverityAlert is action must be taken immediately
+	SeverityAlert
+	// SeverityCrit is critical conditions
+	SeverityCrit
+	// SeverityErr is error conditions
+	SeverityErr
+	// SeverityWarning is warning conditions
+	SeverityWarning
+	// SeverityNotice is normal but significant condition
+	SeverityNotice
+	// SeverityInfo is informational messages
+	SeverityInfo
+	// SeverityDebug is debug-level messages
+	SeverityDebug
+)
+
+// Syslog facilities
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthpriv
+	FacilityFtp
+	_
+	_
+	_
+	_
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+const (
+	// RFC5424 is the syslog protocol format with structured data
+	RFC5424 SyslogFormat = iota + 1
+	// RFC3164 is the BSD syslog format
+	RFC3164
+
+	// DefaultSyslogSDID is SD-ID of the structured data element which has level, caller and fields
+	DefaultSyslogSDID = "glg@32473"
+
+	defaultDialTimeout = 5 * time.Second
+
+	frameNone syslogFraming = iota
+	frameOctetCounting
+	frameNewline
+
+	rfc5424TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+	nilValue          = "-"
+)
+
+// localSyslogPaths are the local syslog sockets tried in order
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogWriter is io.WriteCloser and Encoder which sends log entries to syslog.
+// The entries are encoded by SyslogWriter itself, so it should be set by SetWriter, SetLevelWriter,
+// AddWriter or AddLevelWriter directly instead of being wrapped by another writer.
+//
+// TCP connections use octet-counting framing of RFC 6587, unix stream connections terminate messages by newline,
+// and datagram connections (udp and unixgram) send a message per datagram.
+// The connection is established on the first write and reestablished once when a write fails.
+type SyslogWriter struct {
+	mu          sync.Mutex
+	network     string
+	addr        string
+	conn        net.Conn
+	framing     syslogFraming
+	local       bool
+	format      SyslogFormat
+	facility    SyslogFacility
+	hostname    string
+	appName     string
+	pid         string
+	sdID        string
+	severities  map[LEVEL]SyslogSeverity
+	dialTimeout time.Duration
+}
+
+// NewSyslogWriter returns syslog writer which connects to addr on network such as "udp", "tcp", "unix" or "unixgram".
+// Empty network connects to the local syslog socket such as /dev/log.
+func NewSyslogWriter(network, addr string) *SyslogWriter {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = nilValue
+	}
+	return &SyslogWriter{
+		network:     network,
+		addr:        addr,
+		local:       network == "",
+		format:      RFC5424,
+		facility:    FacilityUser,
+		hostname:    hostname,
+		appName:     filepath.Base(os.Args[0]),
+		pid:         strconv.Itoa(os.Getpid()),
+		sdID:        DefaultSyslogSDID,
+		dialTimeout: defaultDialTimeout,
+	}
+}
+
+// SetFormat sets message format, RFC5424 is default
+func (s *SyslogWriter) SetFormat(format SyslogFormat) *SyslogWriter {
+	s.mu.Lock()
+	s.format = format
+	s.mu.Unlock()
+	return s
+}
+
+// SetFacility sets message facility, FacilityUser is default
+func (s *SyslogWriter) SetFacility(facility SyslogFacility) *SyslogWriter {
+	s.mu.Lock()
+	s.facility = facility
+	s.mu.Unlock()
+	return s
+}
+
+// SetHostname sets HOSTNAME of the messages, os.Hostname is default
+func (s *SyslogWriter) SetHostname(hostname string) *SyslogWriter {
+	s.mu.Lock()
+	s.hostname = hostname
+	s.mu.Unlock()
+	return s
+}
+
+// SetAppName sets APP-NAME of RFC 5424 and TAG of RFC 3164 messages, the program name is default
+func (s *SyslogWriter) SetAppName(name string) *SyslogWriter {
+	s.mu.Lock()
+	s.appName = name
+	s.mu.Unlock()
+	return s
+}
+
+// SetStructuredDataID sets SD-ID of RFC 5424 structured data element, empty id omits structured data
+func (s *SyslogWriter) SetStructuredDataID(id string) *SyslogWriter {
+	s.mu.Lock()
+	s.sdID = id
+	s.mu.Unlock()
+	return s
+}
+
+// SetSeverity maps glg level to syslog severity, it is used for custom levels which are SeverityInfo by default
+func (s *SyslogWriter) SetSeverity(lv LEVEL, severity SyslogSeverity) *SyslogWriter {
+	s.mu.Lock()
+	if s.severities == nil {
+		s.severities = make(map[LEVEL]SyslogSeverity)
+	}
+	s.severities[lv] = severity
+	s.mu.Unlock()
+	return s
+}
+
+// SetDialTimeout sets connection timeout
+func (s *SyslogWriter) SetDialTimeout(timeout time.Duration) *SyslogWriter {
+	s.mu.Lock()
+	s.dialTimeout = timeout
+	s.mu.Unlock()
+	return s
+}
+
+// levelSeverity returns default syslog severity of glg level
+func levelSeverity(lv LEVEL) SyslogSeverity {
+	switch lv {
+	case DEBG, TRACE:
+		return SeverityDebug
+	case OK:
+		return SeverityNotice
+	case WARN:
+		return SeverityWarning
+	case ERR, FAIL:
+		return SeverityErr
+	case PANIC, FATAL:
+		return SeverityCrit
+	}
+	return SeverityInfo
+}
+
+// EncodeEntry encodes e as syslog message without framing
+func (s *SyslogWriter) EncodeEntry(b *bytes.Buffer, e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	severity, ok := s.severities[e.Level]
+	if !ok {
+		severity = levelSeverity(e.Level)
+	}
+	b.WriteByte('<')
+	b.WriteString(strconv.Itoa(int(s.facility)<<3 | int(severity)))
+	b.WriteByte('>')
+	if s.format == RFC3164 {
+		s.encode3164(b, e)
+	} else {
+		s.encode5424(b, e)
+	}
+	return nil
+}
+
+func (s *SyslogWriter) encode5424(b *bytes.Buffer, e *Entry) {
+	b.WriteString("1 ")
+	if e.Time.IsZero() {
+		b.WriteString(nilValue)
+	} else {
+		b.Write(e.Time.AppendFormat(b.AvailableBuffer(), rfc5424TimeFormat))
+	}
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(s.hostname, 255))
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(s.appName, 48))
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(s.pid, 128))
+	b.WriteByte(' ')
+	b.WriteString(nilValue)
+	b.WriteByte(' ')
+	if s.sdID == "" {
+		b.WriteString(nilValue)
+	} else {
+		b.WriteByte('[')
+		b.WriteString(s.sdID)
+		appendSDParam(b, "level", e.Tag)
+		if len(e.Caller) != 0 {
+			appendSDParam(b, "caller", e.Caller)
+		}
+		for _, f := range e.Fields {
+			appendSDParam(b, f.Key, fieldValueString(f.Value))
+		}
+		b.WriteByte(']')
+	}
+	if len(e.Message) != 0 {
+		b.WriteByte(' ')
+		b.WriteString(e.Message)
+	}
+}
+
+func (s *SyslogWriter) encode3164(b *bytes.Buffer, e *Entry) {
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	b.Write(t.AppendFormat(b.AvailableBuffer(), time.Stamp))
+	b.WriteByte(' ')
+	// local syslog daemons add the hostname by themselves
+	if !s.local {
+		b.WriteString(syslogHeaderField(s.hostname, 255))
+		b.WriteByte(' ')
+	}
+	b.WriteString(s.appName)
+	b.WriteString("[" + s.pid + "]: ")
+	if len(e.Caller) != 0 {
+		b.WriteString("(" + e.Caller + "): ")
+	}
+	b.WriteString(e.Message)
+	for _, f := range e.Fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(fieldString(f.Value))
+	}
+}
+
+// syslogHeaderField returns header field limited to max printable US-ASCII characters, empty value is NILVALUE
+func syslogHeaderField(val string, max int) string {
+	if val == "" {
+		return nilValue
+	}
+	if len(val) > max {
+		val = val[:max]
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, val)
+}
+
+// appendSDParam writes RFC 5424 SD-PARAM, invalid characters of the name are replaced by '_'
+func appendSDParam(b *bytes.Buffer, name, val string) {
+	b.WriteByte(' ')
+	if name == "" {
+		name = "_"
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	b.WriteString(strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name))
+	b.WriteString(`="`)
+	for i := 0; i < len(val); i++ {
+		switch c := val[i]; c {
+		case '"', '\\', ']':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(val[i])
+	}
+	b.WriteByte('"')
+}
+
+// Write sends b as a syslog message
+func (s *SyslogWriter) Write(b []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for retry := 0; retry < 2; retry++ {
+		if s.conn == nil {
+			err = s.connect()
+			if err != nil {
+				return 0, err
+			}
+		}
+		err = s.send(b)
+		if err == nil {
+			return len(b), nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	return 0, err
+}
+
+func (s *SyslogWriter) send(b []byte) (err error) {
+	switch s.framing {
+	case frameOctetCounting:
+		frame := strconv.AppendInt(make([]byte, 0, len(b)+8), int64(len(b)), 10)
+		_, err = s.conn.Write(append(append(frame, ' '), b...))
+	case frameNewline:
+		_, err = s.conn.Write(append(b[:len(b):len(b)], '\n'))
+	default:
+		_, err = s.conn.Write(b)
+	}
+	return err
+}
+
+func (s *SyslogWriter) connect() error {
+	if s.network != "" {
+		conn, err := net.DialTimeout(s.network, s.addr, s.dialTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog %s %s: %w", s.network, s.addr, err)
+		}
+		s.conn = conn
+		s.framing = networkFraming(s.network)
+		return nil
+	}
+	var errs error
+	paths := localSyslogPaths
+	if s.addr != "" {
+		paths = []string{s.addr}
+	}
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.DialTimeout(network, path, s.dialTimeout)
+			if err == nil {
+				s.conn = conn
+				s.framing = networkFraming(network)
+				return nil
+			}
+			errs = errors.Join(errs, err)
+		}
+	}
+	return fmt.Errorf("failed to connect to local syslog: %w", errs)
+}
+
+func networkFraming(network string) syslogFraming {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return frameOctetCounting
+	case "unix":
+		return frameNewline
+	}
+	return frameNone
+}
+
+// Close closes the connection
+func (s *SyslogWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
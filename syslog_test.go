@@ -0,0 +1,247 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSyslogWriter(network, addr string) *SyslogWriter {
+	s := NewSyslogWriter(network, addr).SetHostname("host").SetAppName("app").SetDialTimeout(time.Second)
+	s.pid = "42"
+	return s
+}
+
+func TestSyslogWriter_EncodeEntry(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	tests := []struct {
+		name  string
+		setup func(s *SyslogWriter)
+		entry Entry
+		want  string
+	}{
+		{
+			name:  "rfc5424 with structured data",
+			entry: Entry{Time: ts, Level: WARN, Tag: "WARN", Caller: "main.go:10", Message: "disk full", Fields: []Field{{Key: "path", Value: `/var/"log"]`}}},
+			want:  `<12>1 2024-01-02T03:04:05.123456Z host app 42 - [glg@32473 level="WARN" caller="main.go:10" path="/var/\"log\"\]"] disk full`,
+		},
+		{
+			name:  "rfc5424 without timestamp and structured data",
+			setup: func(s *SyslogWriter) { s.SetStructuredDataID("").SetFacility(FacilityLocal0) },
+			entry: Entry{Level: DEBG, Tag: "DEBG", Message: "msg"},
+			want:  `<135>1 - host app 42 - - msg`,
+		},
+		{
+			name:  "invalid param names are replaced",
+			entry: Entry{Time: ts, Level: INFO, Tag: "INFO", Message: "msg", Fields: []Field{{Key: "a b=c", Value: 1}}},
+			want:  `<14>1 2024-01-02T03:04:05.123456Z host app 42 - [glg@32473 level="INFO" a_b_c="1"] msg`,
+		},
+		{
+			name:  "rfc3164",
+			setup: func(s *SyslogWriter) { s.SetFormat(RFC3164).SetFacility(FacilityDaemon) },
+			entry: Entry{Time: ts, Level: ERR, Tag: "ERR", Caller: "main.go:10", Message: "failed", Fields: []Field{{Key: "id", Value: "a b"}}},
+			want:  `<27>Jan  2 03:04:05 host app[42]: (main.go:10): failed id="a b"`,
+		},
+		{
+			name:  "custom level severity",
+			setup: func(s *SyslogWriter) { s.SetSeverity(LEVEL(20), SeverityAlert).SetStructuredDataID("") },
+			entry: Entry{Time: ts, Level: LEVEL(20), Tag: "ALERT", Message: "msg"},
+			want:  `<9>1 2024-01-02T03:04:05.123456Z host app 42 - - msg`,
+		},
+		{
+			name:  "custom level defaults to info",
+			setup: func(s *SyslogWriter) { s.SetStructuredDataID("") },
+			entry: Entry{Time: ts, Level: LEVEL(21), Tag: "CUSTOM", Message: "msg"},
+			want:  `<14>1 2024-01-02T03:04:05.123456Z host app 42 - - msg`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSyslogWriter("udp", "127.0.0.1:0")
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+			b := new(bytes.Buffer)
+			if err := s.EncodeEntry(b, &tt.entry); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("EncodeEntry() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyslogWriter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	s := newTestSyslogWriter("udp", pc.LocalAddr().String())
+	defer s.Close()
+	text := new(bytes.Buffer)
+	g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).SetLevelWriter(ERR, s).AddLevelWriter(ERR, text)
+	if err := g.With("k", "v").Error("boom"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(buf[:n]), `<11>1 - host app 42 - [glg@32473 level="ERR" k="v"] boom`; got != want {
+		t.Errorf("message = %s, want %s", got, want)
+	}
+	// the other writer of the level keeps the text format
+	if got, want := text.String(), "[ERR]:\tboom\tk=v\n"; got != want {
+		t.Errorf("text output = %q, want %q", got, want)
+	}
+}
+
+// readOctetCounted reads a message framed by RFC 6587 octet-counting
+func readOctetCounted(r *bufio.Reader) (string, error) {
+	l, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(l, " "))
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return string(b), err
+}
+
+func TestSyslogWriter_TCPReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	msgs := make(chan string, 16)
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			for {
+				msg, err := readOctetCounted(r)
+				if err != nil {
+					break
+				}
+				msgs <- strconv.Itoa(i) + ":" + msg
+				// the first connection is dropped after the first message
+				if i == 0 {
+					break
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	s := newTestSyslogWriter("tcp", ln.Addr().String()).SetStructuredDataID("")
+	defer s.Close()
+	g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).SetLevelWriter(INFO, s)
+
+	if err := g.Info("multi\nline"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := <-msgs, "0:<14>1 - host app 42 - - multi\nline"; got != want {
+		t.Errorf("first message = %q, want %q", got, want)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		g.Info("after reconnect")
+		select {
+		case got := <-msgs:
+			if want := "1:<14>1 - host app 42 - - after reconnect"; got != want {
+				t.Errorf("message = %q, want %q", got, want)
+			}
+			return
+		case <-deadline:
+			t.Fatal("message is not delivered after reconnect")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestSyslogWriter_Local(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	pc, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer pc.Close()
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := newTestSyslogWriter("", path).SetFormat(RFC3164)
+	defer s.Close()
+	b := new(bytes.Buffer)
+	if err := s.EncodeEntry(b, &Entry{Time: ts, Level: INFO, Tag: "INFO", Message: "local"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write(b.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := pc.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// local syslog adds the hostname
+	if got, want := string(buf[:n]), "<14>Jan  2 03:04:05 app[42]: local"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestSyslogWriter_ConnectError(t *testing.T) {
+	s := NewSyslogWriter("", filepath.Join(t.TempDir(), "missing"))
+	if _, err := s.Write([]byte("msg")); err == nil {
+		t.Error("Write() error = nil, want error")
+	}
+	var opErr *net.OpError
+	if _, err := NewSyslogWriter("tcp", "127.0.0.1:1").SetDialTimeout(time.Second).Write([]byte("msg")); !errors.As(err, &opErr) {
+		t.Errorf("Write() error = %v, want *net.OpError", err)
+	}
+}
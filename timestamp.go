@@ -0,0 +1,186 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kpango/fastime"
+)
+
+const (
+	// DefaultTimeFormat is the default timestamp layout
+	DefaultTimeFormat = timeFormat
+	// TimeFormatMicro is the default timestamp layout with microseconds
+	TimeFormatMicro = "2006-01-02 15:04:05.000000"
+	// TimeFormatUnix formats timestamp as seconds since the Unix epoch
+	TimeFormatUnix = "unix"
+	// TimeFormatUnixMilli formats timestamp as milliseconds since the Unix epoch
+	TimeFormatUnixMilli = "unixmilli"
+	// TimeFormatUnixMicro formats timestamp as microseconds since the Unix epoch
+	TimeFormatUnixMicro = "unixmicro"
+)
+
+// timeSetting is timestamp layout and location, the zero values are inherited from the instance setting
+type timeSetting struct {
+	layout string
+	loc    *time.Location
+}
+
+// timestamp formats time by layout in loc and caches the result for the time in the same unit
+type timestamp struct {
+	layout string
+	loc    *time.Location
+	unit   int64
+	// precise is set when the layout has sub-second precision which the cached clock cannot provide
+	precise bool
+	cache   atomic.Pointer[cachedTimestamp]
+}
+
+type cachedTimestamp struct {
+	key int64
+	str string
+}
+
+func newTimestamp(layout string, loc *time.Location) *timestamp {
+	ts := &timestamp{
+		layout: layout,
+		loc:    loc,
+		unit:   1,
+	}
+	switch {
+	case layout == TimeFormatUnix:
+		ts.unit = int64(time.Second)
+	case layout == TimeFormatUnixMilli:
+		ts.unit = int64(time.Millisecond)
+	case layout == TimeFormatUnixMicro:
+		ts.unit = int64(time.Microsecond)
+	case !strings.ContainsAny(layout, ".,"):
+		// the layout without fractional seconds changes every second at most
+		ts.unit = int64(time.Second)
+	}
+	ts.precise = ts.unit < int64(time.Second)
+	return ts
+}
+
+// format returns t formatted, the string is reused while t is in the same unit
+func (ts *timestamp) format(t time.Time) string {
+	key := t.UnixNano() / ts.unit
+	if c := ts.cache.Load(); c != nil && c.key == key {
+		return c.str
+	}
+	var str string
+	switch ts.layout {
+	case TimeFormatUnix, TimeFormatUnixMilli, TimeFormatUnixMicro:
+		str = strconv.FormatInt(key, 10)
+	default:
+		str = t.In(ts.loc).Format(ts.layout)
+	}
+	ts.cache.Store(&cachedTimestamp{key: key, str: str})
+	return str
+}
+
+// SetTimeFormat sets timestamp layout of all levels such as time.RFC3339Nano, TimeFormatMicro or TimeFormatUnixMilli.
+// The layouts set by SetLevelTimeFormat are kept.
+func (g *Glg) SetTimeFormat(layout string) *Glg {
+	if layout == "" {
+		layout = DefaultTimeFormat
+	}
+	ts := *g.timeSetting.Load()
+	ts.layout = layout
+	g.timeSetting.Store(&ts)
+	g.updateLoggers(func(l *logger) {
+		g.updateTimestamp(l)
+	})
+	return g
+}
+
+// SetLevelTimeFormat sets timestamp layout of the level, empty layout resets to the layout of all levels
+func (g *Glg) SetLevelTimeFormat(lv LEVEL, layout string) *Glg {
+	g.updateLogger(lv, func(l *logger) {
+		l.timeSetting.layout = layout
+		g.updateTimestamp(l)
+	})
+	return g
+}
+
+// SetLevelTimeLocation sets timestamp location of the level, nil resets to the location of all levels
+func (g *Glg) SetLevelTimeLocation(lv LEVEL, loc *time.Location) *Glg {
+	g.updateLogger(lv, func(l *logger) {
+		l.timeSetting.loc = loc
+		g.updateTimestamp(l)
+	})
+	return g
+}
+
+// GetTimeFormat returns timestamp layout of all levels
+func (g *Glg) GetTimeFormat() string {
+	return g.timeSetting.Load().layout
+}
+
+// SetClock sets the function which returns the time of log entries, nil resets to the default clock.
+// It makes timestamps deterministic in tests.
+func (g *Glg) SetClock(now func() time.Time) *Glg {
+	if now == nil {
+		g.clock.Store(nil)
+		return g
+	}
+	g.clock.Store(&now)
+	return g
+}
+
+// now returns the time from the clock set by SetClock or the cached clock refreshed every few milliseconds
+func (g *Glg) now() time.Time {
+	if now := g.clock.Load(); now != nil {
+		return (*now)()
+	}
+	return fastime.Now()
+}
+
+// entryTime returns the time of the entry of l,
+// the current time is read instead of the cached clock when the timestamp layout of l has sub-second precision.
+func (g *Glg) entryTime(l *logger) time.Time {
+	if l.timestamp.precise && g.clock.Load() == nil {
+		return time.Now()
+	}
+	return g.now()
+}
+
+// updateTimestamp sets timestamp formatter of l from the level and instance settings
+func (g *Glg) updateTimestamp(l *logger) {
+	ts := g.timeSetting.Load()
+	layout, loc := l.timeSetting.layout, l.timeSetting.loc
+	if layout == "" {
+		layout = ts.layout
+	}
+	if loc == nil {
+		loc = ts.loc
+	}
+	if l.timestamp == nil || l.timestamp.layout != layout || l.timestamp.loc != loc {
+		l.timestamp = newTimestamp(layout, loc)
+	}
+}
@@ -0,0 +1,234 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_timestamp_format(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	base := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	tests := []struct {
+		name   string
+		layout string
+		loc    *time.Location
+		times  []time.Time
+		want   []string
+	}{
+		{
+			name:   "default layout is cached per second",
+			layout: DefaultTimeFormat,
+			loc:    time.UTC,
+			times:  []time.Time{base, base.Add(500 * time.Millisecond), base.Add(time.Second)},
+			want:   []string{"2024-01-02 03:04:05", "2024-01-02 03:04:05", "2024-01-02 03:04:06"},
+		},
+		{
+			name:   "location",
+			layout: time.RFC3339,
+			loc:    tokyo,
+			times:  []time.Time{base},
+			want:   []string{"2024-01-02T12:04:05+09:00"},
+		},
+		{
+			name:   "rfc3339nano is not cached across nanoseconds",
+			layout: time.RFC3339Nano,
+			loc:    time.UTC,
+			times:  []time.Time{base, base.Add(1)},
+			want:   []string{"2024-01-02T03:04:05.123456789Z", "2024-01-02T03:04:05.12345679Z"},
+		},
+		{
+			name:   "microseconds",
+			layout: TimeFormatMicro,
+			loc:    time.UTC,
+			times:  []time.Time{base},
+			want:   []string{"2024-01-02 03:04:05.123456"},
+		},
+		{
+			name:   "unix seconds",
+			layout: TimeFormatUnix,
+			loc:    tokyo,
+			times:  []time.Time{base, base.Add(time.Second)},
+			want:   []string{"1704164645", "1704164646"},
+		},
+		{
+			name:   "unix milliseconds",
+			layout: TimeFormatUnixMilli,
+			loc:    time.UTC,
+			times:  []time.Time{base, base.Add(time.Millisecond)},
+			want:   []string{"1704164645123", "1704164645124"},
+		},
+		{
+			name:   "unix microseconds",
+			layout: TimeFormatUnixMicro,
+			loc:    time.UTC,
+			times:  []time.Time{base},
+			want:   []string{"1704164645123456"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTimestamp(tt.layout, tt.loc)
+			for i, tm := range tt.times {
+				if got := ts.format(tm); got != tt.want[i] {
+					t.Errorf("format(%v) = %s, want %s", tm, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGlg_SetTimeFormat(t *testing.T) {
+	newLogger := func(buf *bytes.Buffer) *Glg {
+		return New().SetMode(WRITER).SetWriter(buf).EnableTimestamp().SetLineTraceMode(TraceLineNone)
+	}
+	tests := []struct {
+		name  string
+		setup func(g *Glg) *Glg
+		log   func(g *Glg) error
+		want  string
+	}{
+		{
+			name:  "default",
+			setup: func(g *Glg) *Glg { return g },
+			log:   func(g *Glg) error { return g.Info("msg") },
+			want:  `^\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\t\[INFO\]:\tmsg\n$`,
+		},
+		{
+			name: "rfc3339nano in location",
+			setup: func(g *Glg) *Glg {
+				return g.SetTimeFormat(time.RFC3339Nano).SetTimeLocation(time.FixedZone("X", -3*60*60))
+			},
+			log:  func(g *Glg) error { return g.Info("msg") },
+			want: `^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(\.\d+)?-03:00\t\[INFO\]:\tmsg\n$`,
+		},
+		{
+			name:  "level format",
+			setup: func(g *Glg) *Glg { return g.SetLevelTimeFormat(WARN, TimeFormatUnixMilli) },
+			log: func(g *Glg) error {
+				g.Warn("warn")
+				return g.Info("info")
+			},
+			want: `^\d{13}\t\[WARN\]:\twarn\n\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\t\[INFO\]:\tinfo\n$`,
+		},
+		{
+			name:  "level location",
+			setup: func(g *Glg) *Glg { return g.SetTimeFormat(time.RFC3339).SetLevelTimeLocation(ERR, time.UTC) },
+			log:   func(g *Glg) error { return g.Error("msg") },
+			want:  `^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ\t\[ERR\]:\tmsg\n$`,
+		},
+		{
+			name:  "format of all levels keeps level format",
+			setup: func(g *Glg) *Glg { return g.SetLevelTimeFormat(INFO, time.RFC3339).SetTimeFormat(TimeFormatUnix) },
+			log: func(g *Glg) error {
+				g.Warn("warn")
+				return g.Info("info")
+			},
+			want: `^\d{10}\t\[WARN\]:\twarn\n\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(Z|[+-]\d\d:\d\d)\t\[INFO\]:\tinfo\n$`,
+		},
+		{
+			name: "location of all levels keeps level location",
+			setup: func(g *Glg) *Glg {
+				return g.SetTimeFormat(time.RFC3339).SetLevelTimeLocation(ERR, time.UTC).SetTimeLocation(time.FixedZone("X", 3600))
+			},
+			log: func(g *Glg) error {
+				g.Info("info")
+				return g.Error("err")
+			},
+			want: `^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\+01:00\t\[INFO\]:\tinfo\n\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ\t\[ERR\]:\terr\n$`,
+		},
+		{
+			name:  "json date",
+			setup: func(g *Glg) *Glg { return g.EnableJSON().SetTimeFormat(TimeFormatUnixMicro) },
+			log:   func(g *Glg) error { return g.Info("msg") },
+			want:  `^\{"date":"\d{16}","level":"INFO","detail":"msg"\}\n$`,
+		},
+		{
+			name: "custom level",
+			setup: func(g *Glg) *Glg {
+				g.SetTimeFormat(TimeFormatUnix).AddStdLevel("CUSTOM", WRITER, false)
+				l, _ := g.logger.Load(INFO)
+				return g.SetLevelWriter(g.TagStringToLevel("CUSTOM"), l.writer)
+			},
+			log:  func(g *Glg) error { return g.CustomLog("CUSTOM", "msg") },
+			want: `^\d{10}\t\[CUSTOM\]:\tmsg\n$`,
+		},
+		{
+			name: "clock",
+			setup: func(g *Glg) *Glg {
+				now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+				return g.SetClock(func() time.Time { return now }).SetTimeLocation(time.UTC)
+			},
+			log:  func(g *Glg) error { return g.Info("msg") },
+			want: `^2024-01-02 03:04:05\t\[INFO\]:\tmsg\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := tt.setup(newLogger(buf))
+			if err := tt.log(g); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); !regexp.MustCompile(tt.want).MatchString(got) {
+				t.Errorf("output = %q, want match %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_SetTimeFormatPrecision(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).SetTimeFormat(TimeFormatMicro)
+	for i := 0; i < 5; i++ {
+		g.Info("msg")
+		time.Sleep(100 * time.Microsecond)
+	}
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		ts, _, _ := strings.Cut(line, "\t")
+		if seen[ts] {
+			t.Errorf("timestamp %s is repeated in %q", ts, buf.String())
+		}
+		seen[ts] = true
+	}
+}
+
+func TestGlg_SetTimeLocationPerInstance(t *testing.T) {
+	a := New().SetTimeLocation(time.UTC)
+	b := New().SetTimeLocation(time.FixedZone("X", 3600))
+	if a.GetTimeLocation() != time.UTC {
+		t.Errorf("GetTimeLocation() = %v, want UTC", a.GetTimeLocation())
+	}
+	if got := b.GetTimeLocation().String(); got != "X" {
+		t.Errorf("GetTimeLocation() = %v, want X", got)
+	}
+	if got := New().GetTimeLocation(); got != time.Local {
+		t.Errorf("GetTimeLocation() of new instance = %v, want Local", got)
+	}
+}